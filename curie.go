@@ -0,0 +1,168 @@
+package odin_iri
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// PrefixMap maps CURIE prefixes (W3C CURIE Syntax 1.0) to namespace IRIs. The empty prefix is the
+// default prefix, used by ":name" and by references written without any ':'.
+type PrefixMap struct {
+	prefixes map[string]string
+}
+
+// NewPrefixMap returns an empty prefix map.
+func NewPrefixMap() *PrefixMap {
+	return &PrefixMap{prefixes: make(map[string]string)}
+}
+
+// Set maps prefix to namespace, replacing any previous mapping. The prefix must be empty or an
+// NCName other than "_", which is reserved for blank nodes, and the namespace must be an IRI.
+func (m *PrefixMap) Set(prefix, namespace string) error {
+	if prefix != "" && !isNCName(prefix) {
+		return errors.New("curie prefix must be an NCName")
+	}
+	if prefix == "_" {
+		return errors.New("the curie prefix '_' is reserved for blank nodes")
+	}
+	if _, err := ParseIri(namespace); err != nil {
+		return err
+	}
+	m.prefixes[prefix] = namespace
+	return nil
+}
+
+// Namespace returns the namespace mapped to prefix.
+func (m *PrefixMap) Namespace(prefix string) (string, bool) {
+	namespace, ok := m.prefixes[prefix]
+	return namespace, ok
+}
+
+// Delete removes the mapping of prefix.
+func (m *PrefixMap) Delete(prefix string) {
+	delete(m.prefixes, prefix)
+}
+
+// Expand turns a CURIE or safe CURIE into an IRI by appending its reference to the namespace of
+// its prefix.
+//
+// Most IRIs are also syntactically valid CURIEs, so a value outside of brackets follows the RDFa
+// and JSON-LD rule: a CURIE whose prefix is mapped always expands, while a value whose reference
+// starts with "//", whose prefix is unmapped or that is not a CURIE at all is returned as the IRI
+// it is. So "http://example.org/" stays an IRI even with a "http" prefix, and "http:Request"
+// expands. A safe CURIE such as "[ex://x]" is always expanded.
+func (m *PrefixMap) Expand(value string) (*IRI, error) {
+	curie, safe := unwrapSafeCURIE(value)
+	prefix, reference, err := splitCURIE(curie)
+	namespace, mapped := m.prefixes[prefix]
+	if !safe && (err != nil || !mapped || strings.HasPrefix(reference, "//")) {
+		if iri, err := ParseIri(value); err == nil {
+			return iri, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !mapped {
+		return nil, errors.New("curie prefix is not mapped: " + prefix)
+	}
+	return ParseIri(namespace + reference)
+}
+
+// Compact returns the CURIE of the IRI using the longest namespace it starts with, the rest of
+// the IRI becoming the reference. Prefixes mapped to the same namespace are tried in alphabetical
+// order. A reference starting with "//" produces a safe CURIE, as Expand would otherwise read it
+// back as an IRI. Compact reports false when no namespace leaves a valid reference.
+func (m *PrefixMap) Compact(iri *IRI) (string, bool) {
+	prefixes := make([]string, 0, len(m.prefixes))
+	for prefix, namespace := range m.prefixes {
+		if strings.HasPrefix(iri.Value, namespace) && isIRelativeRef(iri.Value[len(namespace):]) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return "", false
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := m.prefixes[prefixes[i]], m.prefixes[prefixes[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	prefix := prefixes[0]
+	reference := iri.Value[len(m.prefixes[prefix]):]
+	curie := prefix + ":" + reference
+	if strings.HasPrefix(reference, "//") {
+		curie = "[" + curie + "]"
+	}
+	return curie, true
+}
+
+// ValidateCURIE checks the syntax of a CURIE or safe CURIE: an optional NCName prefix and ':'
+// followed by a reference that is an irelative-ref.
+func ValidateCURIE(value string) error {
+	curie, _ := unwrapSafeCURIE(value)
+	_, _, err := splitCURIE(curie)
+	return err
+}
+
+// unwrapSafeCURIE removes the brackets of a safe CURIE, reporting whether there were any.
+func unwrapSafeCURIE(value string) (string, bool) {
+	if len(value) >= 2 && value[0] == '[' && value[len(value)-1] == ']' {
+		return value[1 : len(value)-1], true
+	}
+	return value, false
+}
+
+// splitCURIE splits a CURIE into its prefix, empty for the default prefix, and its reference.
+func splitCURIE(curie string) (string, string, error) {
+	prefix, reference, ok := strings.Cut(curie, ":")
+	if !ok {
+		prefix, reference = "", curie
+	}
+	if prefix != "" && !isNCName(prefix) {
+		return "", "", errors.New("curie prefix must be an NCName")
+	}
+	if prefix == "_" {
+		return "", "", errors.New("the curie prefix '_' is reserved for blank nodes")
+	}
+	if !isIRelativeRef(reference) {
+		return "", "", errors.New("curie reference must be an irelative-ref")
+	}
+	return prefix, reference, nil
+}
+
+func isIRelativeRef(value string) bool {
+	p := newParser(value)
+	p.next()
+	_, err := p.parse(p.irelativeRef)
+	return err == nil
+}
+
+// isNCName checks the XML NCName production, a Name without any ':'.
+func isNCName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isNameStartChar(r) && (i == 0 || !isNameChar(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStartChar(r rune) bool {
+	return isAlpha(r) || r == '_' ||
+		r >= 0xC0 && r <= 0xD6 || r >= 0xD8 && r <= 0xF6 || r >= 0xF8 && r <= 0x2FF ||
+		r >= 0x370 && r <= 0x37D || r >= 0x37F && r <= 0x1FFF || r >= 0x200C && r <= 0x200D ||
+		r >= 0x2070 && r <= 0x218F || r >= 0x2C00 && r <= 0x2FEF || r >= 0x3001 && r <= 0xD7FF ||
+		r >= 0xF900 && r <= 0xFDCF || r >= 0xFDF0 && r <= 0xFFFD || r >= 0x10000 && r <= 0xEFFFF
+}
+
+func isNameChar(r rune) bool {
+	return isNameStartChar(r) || isDigit(r) || r == '-' || r == '.' || r == 0xB7 ||
+		r >= 0x300 && r <= 0x36F || r >= 0x203F && r <= 0x2040
+}
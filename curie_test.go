@@ -0,0 +1,188 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func newTestPrefixMap(t *testing.T) *PrefixMap {
+	m := NewPrefixMap()
+	mappings := map[string]string{
+		"foaf":   "http://xmlns.com/foaf/0.1/",
+		"ex":     "http://example.org/",
+		"exns":   "http://example.org/ns/",
+		"alt":    "http://example.org/ns/",
+		"":       "http://example.org/default#",
+		"http":   "http://www.w3.org/2011/http#",
+		"dé":     "http://example.org/dé/",
+		"bad_ok": "urn:example:",
+		"geo":    "http://www.w3.org/2003/01/geo/wgs84_pos#",
+	}
+	for prefix, namespace := range mappings {
+		if err := m.Set(prefix, namespace); err != nil {
+			t.Fatalf("Set should succeed with '%s': %s", prefix, err.Error())
+		}
+	}
+	return m
+}
+
+func TestPrefixMapExpand(t *testing.T) {
+	set := map[string]string{
+		"foaf:name":            "http://xmlns.com/foaf/0.1/name",
+		"[foaf:name]":          "http://xmlns.com/foaf/0.1/name",
+		"ex:a/b?c#d":           "http://example.org/a/b?c#d",
+		"ex:":                  "http://example.org/",
+		"exns:Thing":           "http://example.org/ns/Thing",
+		":local":               "http://example.org/default#local",
+		"local":                "http://example.org/default#local",
+		"dé:ü":                 "http://example.org/dé/ü",
+		"bad_ok:x":             "urn:example:x",
+		"ex://not-authority":   "ex://not-authority",
+		"[ex://not-authority]": "http://example.org///not-authority",
+		"http://example.org/":  "http://example.org/",
+		"http:Request":         "http://www.w3.org/2011/http#Request",
+		"[http:Request]":       "http://www.w3.org/2011/http#Request",
+		"mailto:a@example.org": "mailto:a@example.org",
+		"unmapped:x":           "unmapped:x",
+		"geo:lat":              "http://www.w3.org/2003/01/geo/wgs84_pos#lat",
+	}
+
+	m := newTestPrefixMap(t)
+	for v, e := range set {
+		iri, err := m.Expand(v)
+		if err != nil {
+			t.Fatalf("Expand should succeed with '%s': %s", v, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("'%s' should expand to '%s', got '%s'", v, e, iri.Value)
+		}
+	}
+
+	failSet := []string{
+		"[unmapped:x]",
+		"[mailto:a@example.org]",
+		"_:b0",
+		"[_:b0]",
+		"1x:y",
+		"foaf:a b",
+		"[foaf:name",
+		"un_mapped:x",
+	}
+	for _, v := range failSet {
+		if _, err := m.Expand(v); err == nil {
+			t.Fatalf("Expand should fail with '%s'", v)
+		}
+	}
+
+	empty := NewPrefixMap()
+	if _, err := empty.Expand("name"); err == nil {
+		t.Fatalf("a reference without a default prefix should fail")
+	}
+}
+
+func TestPrefixMapCompact(t *testing.T) {
+	set := map[string]string{
+		"http://xmlns.com/foaf/0.1/name":              "foaf:name",
+		"http://example.org/ns/Thing":                 "alt:Thing",
+		"http://example.org/other":                    "ex:other",
+		"http://example.org/default#local":            ":local",
+		"http://example.org/dé/ü":                     "dé:ü",
+		"http://www.w3.org/2011/http#Request":         "http:Request",
+		"http://www.w3.org/2003/01/geo/wgs84_pos#lat": "geo:lat",
+		"http://example.org///x":                      "[ex://x]",
+		"urn:example:x":                               "bad_ok:x",
+		"http://example.org/":                         "ex:",
+	}
+
+	m := newTestPrefixMap(t)
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		curie, ok := m.Compact(iri)
+		if !ok {
+			t.Fatalf("'%s' should compact", v)
+		}
+		if curie != e {
+			t.Fatalf("'%s' should compact to '%s', got '%s'", v, e, curie)
+		}
+		expanded, err := m.Expand(curie)
+		if err != nil {
+			t.Fatalf("'%s' should expand: %s", curie, err.Error())
+		}
+		if expanded.Value != v {
+			t.Fatalf("'%s' should expand back to '%s', got '%s'", curie, v, expanded.Value)
+		}
+	}
+
+	failSet := []string{
+		"https://example.org/x",
+		"http://example.org:80/x",
+		"http://example.org/a:b",
+	}
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if curie, ok := m.Compact(iri); ok {
+			t.Fatalf("'%s' should not compact, got '%s'", v, curie)
+		}
+	}
+}
+
+func TestValidateCURIE(t *testing.T) {
+	goodSet := []string{
+		"foaf:name",
+		"[foaf:name]",
+		":name",
+		"name",
+		"ex:",
+		"ex:a/b?c#d",
+		"ex://host/path",
+		"é.x-1:y",
+		"_a:b",
+		"",
+	}
+	failSet := []string{
+		"_:b0",
+		"1a:b",
+		"-a:b",
+		".a:b",
+		"a b:c",
+		"a:b c",
+		"a:b:c",
+		"[a:b c]",
+		"a:%zz",
+	}
+
+	for _, v := range goodSet {
+		if err := ValidateCURIE(v); err != nil {
+			t.Fatalf("ValidateCURIE should succeed with '%s': %s", v, err.Error())
+		}
+	}
+	for _, v := range failSet {
+		if err := ValidateCURIE(v); err == nil {
+			t.Fatalf("ValidateCURIE should fail with '%s'", v)
+		}
+	}
+
+	m := NewPrefixMap()
+	if err := m.Set("1x", "http://example.org/"); err == nil {
+		t.Fatalf("Set should reject an invalid prefix")
+	}
+	if err := m.Set("_", "http://example.org/"); err == nil {
+		t.Fatalf("Set should reject the blank node prefix")
+	}
+	if err := m.Set("ex", "not an iri"); err == nil {
+		t.Fatalf("Set should reject an invalid namespace")
+	}
+	_ = m.Set("ex", "http://example.org/")
+	if ns, ok := m.Namespace("ex"); !ok || ns != "http://example.org/" {
+		t.Fatalf("Namespace should return the mapping")
+	}
+	m.Delete("ex")
+	if _, ok := m.Namespace("ex"); ok {
+		t.Fatalf("Delete should remove the mapping")
+	}
+}
@@ -0,0 +1,171 @@
+package odin_iri
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// DataURI is the content of a "data:" IRI (RFC 2397).
+type DataURI struct {
+	// MediaType is the lowercased type/subtype, "text/plain" when the IRI omits it.
+	MediaType string
+	// Params holds the media type parameters keyed by lowercased attribute with decoded values.
+	// Without a media type the charset defaults to "US-ASCII".
+	Params map[string]string
+	// Base64 is set when the payload is base64 encoded.
+	Base64 bool
+	// Payload is the data after the ',' in its pct-encoded form.
+	Payload string
+}
+
+// ParseDataURI extracts the media type, parameters and payload of a "data:" IRI. A '?' in the
+// payload starts the query of the IRI, so the query is joined back onto the payload.
+func ParseDataURI(iri *IRI) (*DataURI, error) {
+	if !strings.EqualFold(iri.Scheme, "data") {
+		return nil, errors.New("not a data uri")
+	}
+	if iri.Spans().Host.Present() {
+		return nil, errors.New("a data uri cannot have an authority")
+	}
+	header, payload, ok := strings.Cut(iri.Path, ",")
+	if !ok {
+		return nil, errors.New("data uri is missing ','")
+	}
+	if iri.Spans().Query.Present() {
+		payload += "?" + iri.Query
+	}
+	d := &DataURI{Params: make(map[string]string), Payload: payload}
+
+	parameters := strings.Split(header, ";")
+	if mediaType := parameters[0]; mediaType != "" {
+		mainType, subType, ok := strings.Cut(mediaType, "/")
+		if !ok || !isToken(mainType) || !isToken(subType) {
+			return nil, errors.New("invalid data uri media type")
+		}
+		d.MediaType = strings.ToLower(mediaType)
+	}
+	parameters = parameters[1:]
+	if n := len(parameters); n > 0 && strings.EqualFold(parameters[n-1], "base64") {
+		d.Base64 = true
+		parameters = parameters[:n-1]
+	}
+	for _, parameter := range parameters {
+		attribute, value, ok := strings.Cut(parameter, "=")
+		if !ok || !isToken(attribute) {
+			return nil, errors.New("invalid data uri parameter")
+		}
+		decoded, err := Decode(value)
+		if err != nil {
+			return nil, err
+		}
+		d.Params[strings.ToLower(attribute)] = decoded
+	}
+	if d.MediaType == "" {
+		d.MediaType = "text/plain"
+		if _, ok := d.Params["charset"]; !ok {
+			d.Params["charset"] = "US-ASCII"
+		}
+	}
+	return d, nil
+}
+
+// Reader returns a reader over the decoded payload. Pct-encoding and base64 are decoded as the
+// reader is consumed, so large payloads are never decoded in one piece.
+func (d *DataURI) Reader() io.Reader {
+	var r io.Reader = &pctReader{r: strings.NewReader(d.Payload)}
+	if d.Base64 {
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return r
+}
+
+// Bytes decodes the whole payload.
+func (d *DataURI) Bytes() ([]byte, error) {
+	return io.ReadAll(d.Reader())
+}
+
+// NewDataIRI builds a "data:" IRI holding data. mediaType may carry parameters, as in
+// "text/plain; charset=utf-8", and may be empty for the RFC 2397 default. The payload is base64
+// encoded when useBase64 is set and pct-encoded otherwise.
+func NewDataIRI(mediaType string, data []byte, useBase64 bool) (*IRI, error) {
+	b := strings.Builder{}
+	b.WriteString("data:")
+	if mediaType != "" {
+		parsedType, params, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(parsedType)
+		attributes := make([]string, 0, len(params))
+		for attribute := range params {
+			attributes = append(attributes, attribute)
+		}
+		sort.Strings(attributes)
+		for _, attribute := range attributes {
+			b.WriteString(";" + attribute + "=")
+			b.WriteString(encodeDataPart(params[attribute], true))
+		}
+	}
+	if useBase64 {
+		b.WriteString(";base64,")
+		b.WriteString(base64.StdEncoding.EncodeToString(data))
+	} else {
+		b.WriteByte(',')
+		b.WriteString(encodeDataPart(string(data), false))
+	}
+	return ParseIri(b.String())
+}
+
+// encodeDataPart pct-encodes a parameter value or payload so it stays within the path of the IRI.
+// Parameter values additionally escape the ';', '=' and ',' that delimit them.
+func encodeDataPart(value string, parameter bool) string {
+	encoded := strings.ReplaceAll(Encode(FragmentComponent, value), "?", "%3F")
+	if parameter {
+		encoded = strings.NewReplacer(";", "%3B", "=", "%3D", ",", "%2C").Replace(encoded)
+	}
+	return encoded
+}
+
+// pctReader decodes pct-encoded triples while reading from r.
+type pctReader struct {
+	r io.ByteReader
+}
+
+func (p *pctReader) Read(b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		if c == '%' {
+			hex := make([]byte, 2)
+			for i := range hex {
+				if hex[i], err = p.r.ReadByte(); err != nil || !isHexDigit(rune(hex[i])) {
+					return n, InvalidEncodingError
+				}
+			}
+			c = pctDecode("%" + string(hex))[0]
+		}
+		b[n] = c
+		n++
+	}
+	return n, nil
+}
+
+// isToken reports whether value is a non-empty RFC 2045 token.
+func isToken(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?=`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package odin_iri
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDataURI(t *testing.T) {
+	type expected struct {
+		mediaType string
+		params    map[string]string
+		base64    bool
+		data      string
+	}
+	set := map[string]expected{
+		"data:,A%20brief%20note":                 {"text/plain", map[string]string{"charset": "US-ASCII"}, false, "A brief note"},
+		"data:;charset=utf-8,gr%C3%BC%C3%9F":     {"text/plain", map[string]string{"charset": "utf-8"}, false, "grüß"},
+		"data:text/plain;charset=UTF-8,grüß?x=1": {"text/plain", map[string]string{"charset": "UTF-8"}, false, "grüß?x=1"},
+		"data:Image/GIF;base64,R0lGODdhAQABAIAA": {"image/gif", map[string]string{}, true, "GIF87a\x01\x00\x01\x00\x80\x00"},
+		"data:text/plain;BASE64,SGVsbG8=#frag":   {"text/plain", map[string]string{}, true, "Hello"},
+		"data:application/x-t;a=b%3Bc;d=e,x":     {"application/x-t", map[string]string{"a": "b;c", "d": "e"}, false, "x"},
+		"data:text/plain;base64,SGVs%0AbG8=":     {"text/plain", map[string]string{}, true, "Hello"},
+	}
+
+	for v, e := range set {
+		d := mustParse(t, v, ParseDataURI)
+		if d.MediaType != e.mediaType || d.Base64 != e.base64 || !reflect.DeepEqual(d.Params, e.params) {
+			t.Fatalf("unexpected header for '%s': %+v", v, d)
+		}
+		data, err := d.Bytes()
+		if err != nil {
+			t.Fatalf("decoding '%s' failed: %s", v, err.Error())
+		}
+		if string(data) != e.data {
+			t.Fatalf("data of '%s' should be %q, got %q", v, e.data, data)
+		}
+	}
+
+	failSet := []string{
+		"http://example.org/,x",
+		"data:text/plain",
+		"data:text,x",
+		"data:text/plain;charset,x",
+		"data:te(xt/plain,x",
+		"data:;a=%ZZ,x",
+	}
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			continue
+		}
+		if _, err = ParseDataURI(iri); err == nil {
+			t.Fatalf("ParseDataURI should have failed with %s", v)
+		}
+	}
+}
+
+func TestDataURIReader(t *testing.T) {
+	payload := strings.Repeat("0123456789", 1000)
+	iri, err := NewDataIRI("application/octet-stream", []byte(payload), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ParseDataURI(iri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := d.Reader()
+	chunk := make([]byte, 7)
+	read := bytes.Buffer{}
+	for {
+		n, err := r.Read(chunk)
+		read.Write(chunk[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if read.String() != payload {
+		t.Fatalf("streamed payload should match the original")
+	}
+
+	d = &DataURI{Payload: "bad%2"}
+	if _, err = d.Bytes(); err != InvalidEncodingError {
+		t.Fatalf("a truncated pct-encoding should fail, got %v", err)
+	}
+}
+
+func TestNewDataIRI(t *testing.T) {
+	set := []struct {
+		mediaType string
+		data      string
+		base64    bool
+		expected  string
+	}{
+		{"", "Hello, World!", false, "data:,Hello,%20World!"},
+		{"text/plain; charset=utf-8", "grüß?#%", false, "data:text/plain;charset=utf-8,grüß%3F%23%25"},
+		{"text/plain; title=\"a;b=c, d\"", "x", false, "data:text/plain;title=a%3Bb%3Dc%2C%20d,x"},
+		{"Image/PNG", "\x89PNG", true, "data:image/png;base64,iVBORw=="},
+	}
+
+	for _, v := range set {
+		iri, err := NewDataIRI(v.mediaType, []byte(v.data), v.base64)
+		if err != nil {
+			t.Fatalf("NewDataIRI should succeed with %q: %s", v.mediaType, err.Error())
+		}
+		if iri.Value != v.expected {
+			t.Fatalf("data IRI should be '%s', got '%s'", v.expected, iri.Value)
+		}
+		d, err := ParseDataURI(iri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := d.Bytes()
+		if err != nil || string(data) != v.data {
+			t.Fatalf("data IRI '%s' should decode to %q, got %q (%v)", iri.Value, v.data, data, err)
+		}
+	}
+	if _, err := NewDataIRI("not a media type", nil, false); err == nil {
+		t.Fatalf("NewDataIRI should reject an invalid media type")
+	}
+}
@@ -0,0 +1,188 @@
+package odin_iri
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DID is a Decentralized Identifier (W3C DID Core section 3.1).
+type DID struct {
+	// Method is the method name, such as "web" or "key".
+	Method string
+	// ID is the method-specific identifier, kept in its pct-encoded form.
+	ID string
+}
+
+// DIDURL is a DID followed by an optional path, query and fragment (W3C DID Core section 3.2).
+type DIDURL struct {
+	DID DID
+	// Path is the path-abempty that follows the DID, either empty or starting with '/'.
+	Path string
+	// Query holds the query, when HasQuery is set.
+	Query    string
+	HasQuery bool
+	// Fragment holds the fragment, when HasFragment is set.
+	Fragment    string
+	HasFragment bool
+}
+
+var (
+	didMethodSyntax = regexp.MustCompile(`^[a-z0-9]+$`)
+	didIDSyntax     = regexp.MustCompile(`^(([A-Za-z0-9._-]|%[0-9A-Fa-f]{2})*:)*([A-Za-z0-9._-]|%[0-9A-Fa-f]{2})+$`)
+)
+
+// ParseDID extracts a DID from an IRI, which must not have a path, query or fragment after the
+// method-specific identifier.
+func ParseDID(iri *IRI) (*DID, error) {
+	u, err := ParseDIDURL(iri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path != "" || u.HasQuery || u.HasFragment {
+		return nil, errors.New("a did cannot have a path, query or fragment")
+	}
+	return &u.DID, nil
+}
+
+// ParseDIDURL extracts a DID URL from an IRI. The "did" scheme and the method name must be
+// lowercase and the method-specific identifier may only hold ASCII letters, digits, '.', '-',
+// '_', ':' and pct-encoded octets, with a non-empty last ':' separated part.
+func ParseDIDURL(iri *IRI) (*DIDURL, error) {
+	if iri.Scheme != "did" {
+		return nil, errors.New("not a did")
+	}
+	if iri.Spans().Host.Present() {
+		return nil, errors.New("a did cannot have an authority")
+	}
+	did, path := iri.Path, ""
+	if slash := strings.IndexByte(did, '/'); slash >= 0 {
+		did, path = did[:slash], did[slash:]
+	}
+	method, id, ok := strings.Cut(did, ":")
+	if !ok {
+		return nil, errors.New("did is missing ':' after the method name")
+	}
+	if !didMethodSyntax.MatchString(method) {
+		return nil, errors.New("invalid did method name")
+	}
+	if !didIDSyntax.MatchString(id) {
+		return nil, errors.New("invalid did method-specific identifier")
+	}
+	u := &DIDURL{DID: DID{Method: method, ID: id}, Path: path}
+	if iri.Spans().Query.Present() {
+		u.Query, u.HasQuery = iri.Query, true
+	}
+	if iri.Spans().Fragment.Present() {
+		u.Fragment, u.HasFragment = iri.Fragment, true
+	}
+	return u, nil
+}
+
+// String returns the DID.
+func (d *DID) String() string {
+	return "did:" + d.Method + ":" + d.ID
+}
+
+// IRI parses the DID back into an IRI.
+func (d *DID) IRI() (*IRI, error) {
+	return ParseIri(d.String())
+}
+
+// Resolve resolves a relative DID URL against the DID. See DIDURL.Resolve.
+func (d *DID) Resolve(ref *IRI) (*DIDURL, error) {
+	return (&DIDURL{DID: *d}).Resolve(ref)
+}
+
+// String returns the DID URL.
+func (u *DIDURL) String() string {
+	b := strings.Builder{}
+	b.WriteString(u.DID.String())
+	b.WriteString(u.Path)
+	if u.HasQuery {
+		b.WriteByte('?')
+		b.WriteString(u.Query)
+	}
+	if u.HasFragment {
+		b.WriteByte('#')
+		b.WriteString(u.Fragment)
+	}
+	return b.String()
+}
+
+// IRI parses the DID URL back into an IRI.
+func (u *DIDURL) IRI() (*IRI, error) {
+	return ParseIri(u.String())
+}
+
+// Resolve resolves a relative DID URL against the DID URL using RFC 3986 section 5.2, the DID
+// acting as the authority of the base: "#key-1" keeps the path and query of the base, "?hl=x"
+// keeps its path, "/a" and "a" resolve below the DID and ".." never climbs above it. References
+// with a scheme must themselves be DID URLs, and network-path references are rejected.
+func (u *DIDURL) Resolve(ref *IRI) (*DIDURL, error) {
+	if ref.Scheme != "" {
+		return ParseDIDURL(ref)
+	}
+	if ref.Spans().Host.Present() {
+		return nil, errors.New("a relative did url cannot have an authority")
+	}
+	base := parts{
+		path:         u.Path,
+		query:        u.Query,
+		fragment:     u.Fragment,
+		hasAuthority: true,
+		hasQuery:     u.HasQuery,
+		hasFragment:  u.HasFragment,
+	}
+	target := resolveParts(base, ref.parts())
+	return &DIDURL{
+		DID:         u.DID,
+		Path:        target.path,
+		Query:       target.query,
+		HasQuery:    target.hasQuery,
+		Fragment:    target.fragment,
+		HasFragment: target.hasFragment,
+	}, nil
+}
+
+// Params parses the query of the DID URL into its DID parameters.
+func (u *DIDURL) Params() (QueryParams, error) {
+	return ParseQuery(u.Query, "&")
+}
+
+// Service returns the "service" DID parameter, naming a service of the DID document.
+func (u *DIDURL) Service() string {
+	return u.param("service")
+}
+
+// RelativeRef returns the "relativeRef" DID parameter, a reference resolved against the endpoint
+// of the service.
+func (u *DIDURL) RelativeRef() string {
+	return u.param("relativeRef")
+}
+
+// VersionID returns the "versionId" DID parameter, identifying a version of the DID document.
+func (u *DIDURL) VersionID() string {
+	return u.param("versionId")
+}
+
+// VersionTime returns the "versionTime" DID parameter, an XML datetime selecting the version of
+// the DID document valid at that time.
+func (u *DIDURL) VersionTime() string {
+	return u.param("versionTime")
+}
+
+// HL returns the "hl" DID parameter, a hashlink of the DID document.
+func (u *DIDURL) HL() string {
+	return u.param("hl")
+}
+
+// param returns a DID parameter, or an empty string when it is missing or the query cannot be
+// decoded.
+func (u *DIDURL) param(name string) string {
+	params, err := u.Params()
+	if err != nil {
+		return ""
+	}
+	return params.Get(name)
+}
@@ -0,0 +1,157 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func TestParseDID(t *testing.T) {
+	set := map[string]DID{
+		"did:example:123456789abcdefghi":                           {"example", "123456789abcdefghi"},
+		"did:web:example.com":                                      {"web", "example.com"},
+		"did:web:example.com%3A8443:user:alice":                    {"web", "example.com%3A8443:user:alice"},
+		"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK": {"key", "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},
+		"did:ion:EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w":   {"ion", "EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w"},
+		"did:example::::x":                                         {"example", ":::x"},
+		"did:m2:a.b_c-d":                                           {"m2", "a.b_c-d"},
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		did, err := ParseDID(iri)
+		if err != nil {
+			t.Fatalf("ParseDID should succeed with '%s': %s", v, err.Error())
+		}
+		if *did != e {
+			t.Fatalf("unexpected did for '%s': %+v", v, did)
+		}
+		if did.String() != v {
+			t.Fatalf("did '%s' should print as itself, got '%s'", v, did.String())
+		}
+	}
+
+	failSet := []string{
+		"urn:example:123",
+		"DID:example:123",
+		"did:Example:123",
+		"did:ex-ample:123",
+		"did:example",
+		"did::123",
+		"did:example:",
+		"did:example:123:",
+		"did:example:12~3",
+		"did:example:日本",
+		"did:example:123/path",
+		"did:example:123?service=x",
+		"did:example:123#key-1",
+		"did://example/123",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := ParseDID(iri); err == nil {
+			t.Fatalf("ParseDID should fail with '%s'", v)
+		}
+	}
+}
+
+func TestParseDIDURL(t *testing.T) {
+	set := map[string]DIDURL{
+		"did:example:123":              {DID: DID{"example", "123"}},
+		"did:example:123/path/to/x":    {DID: DID{"example", "123"}, Path: "/path/to/x"},
+		"did:example:123?versionId=4":  {DID: DID{"example", "123"}, Query: "versionId=4", HasQuery: true},
+		"did:example:123#public-key-0": {DID: DID{"example", "123"}, Fragment: "public-key-0", HasFragment: true},
+		"did:web:example.com:u/p?q#":   {DID: DID{"web", "example.com:u"}, Path: "/p", Query: "q", HasQuery: true, HasFragment: true},
+		"did:example:123/":             {DID: DID{"example", "123"}, Path: "/"},
+		"did:example:123/ü?ä#ö":        {DID: DID{"example", "123"}, Path: "/ü", Query: "ä", HasQuery: true, Fragment: "ö", HasFragment: true},
+	}
+
+	for v, e := range set {
+		u := mustParse(t, v, ParseDIDURL)
+		if *u != e {
+			t.Fatalf("unexpected did url for '%s': %+v", v, u)
+		}
+		if u.String() != v {
+			t.Fatalf("did url '%s' should print as itself, got '%s'", v, u.String())
+		}
+	}
+
+	u := mustParse(t, "did:example:123?service=agent&relativeRef=%2Fcredentials%23degree&versionId=1&versionTime=2021-05-10T17:00:00Z&hl=zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e", ParseDIDURL)
+	if u.Service() != "agent" || u.RelativeRef() != "/credentials#degree" || u.VersionID() != "1" ||
+		u.VersionTime() != "2021-05-10T17:00:00Z" || u.HL() != "zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e" {
+		t.Fatalf("unexpected did parameters for %+v", u)
+	}
+	if u := mustParse(t, "did:example:123", ParseDIDURL); u.Service() != "" || u.HL() != "" {
+		t.Fatalf("a did without a query has no parameters")
+	}
+}
+
+func TestDIDResolve(t *testing.T) {
+	set := map[string]string{
+		"#key-1":                   "did:example:123#key-1",
+		"?service=files":           "did:example:123?service=files",
+		"/path/x":                  "did:example:123/path/x",
+		"path/x":                   "did:example:123/path/x",
+		"../../x":                  "did:example:123/x",
+		"":                         "did:example:123",
+		"did:other:456#k":          "did:other:456#k",
+		"./a/../b?versionId=2#k-2": "did:example:123/b?versionId=2#k-2",
+	}
+
+	iri, _ := ParseIri("did:example:123")
+	did, err := ParseDID(iri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, e := range set {
+		ref, err := ParseIriReference(r)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", r, err.Error())
+		}
+		resolved, err := did.Resolve(ref)
+		if err != nil {
+			t.Fatalf("resolving '%s' should succeed: %s", r, err.Error())
+		}
+		if resolved.String() != e {
+			t.Fatalf("'%s' should resolve to '%s', got '%s'", r, e, resolved.String())
+		}
+	}
+
+	base := mustParse(t, "did:example:123/a/b?versionId=1#k", ParseDIDURL)
+	baseSet := map[string]string{
+		"#key-2": "did:example:123/a/b?versionId=1#key-2",
+		"c":      "did:example:123/a/c",
+		"?hl=x":  "did:example:123/a/b?hl=x",
+		"":       "did:example:123/a/b?versionId=1",
+	}
+	for r, e := range baseSet {
+		ref, _ := ParseIriReference(r)
+		resolved, err := base.Resolve(ref)
+		if err != nil {
+			t.Fatalf("resolving '%s' should succeed: %s", r, err.Error())
+		}
+		if resolved.String() != e {
+			t.Fatalf("'%s' should resolve to '%s', got '%s'", r, e, resolved.String())
+		}
+		if _, err := resolved.IRI(); err != nil {
+			t.Fatalf("'%s' should be an iri: %s", resolved.String(), err.Error())
+		}
+	}
+
+	failSet := []string{
+		"//example.org/x",
+		"http://example.org/x",
+		"did:Bad:x",
+	}
+	for _, r := range failSet {
+		ref, _ := ParseIriReference(r)
+		if _, err := did.Resolve(ref); err == nil {
+			t.Fatalf("resolving '%s' should fail", r)
+		}
+	}
+}
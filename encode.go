@@ -0,0 +1,108 @@
+package odin_iri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// InvalidEncodingError is returned by Decode when a '%' is not followed by two hexadecimal digits.
+var InvalidEncodingError = errors.New("invalid pct encoding")
+
+// InvalidUTF8Error is returned by Decode when the decoded octets are not valid UTF-8.
+var InvalidUTF8Error = errors.New("pct encoding decodes to invalid UTF-8")
+
+// Component identifies the grammar rule a string is encoded for, which decides the characters
+// that may be written without pct-encoding.
+type Component int
+
+const (
+	// UserInfoComponent allows unreserved, sub-delims and ':'.
+	UserInfoComponent Component = iota
+	// RegNameComponent allows unreserved and sub-delims.
+	RegNameComponent
+	// SegmentComponent allows unreserved, sub-delims, ':' and '@'.
+	SegmentComponent
+	// SegmentNzNcComponent allows unreserved, sub-delims and '@', for the first segment of a
+	// relative-path reference.
+	SegmentNzNcComponent
+	// QueryComponent allows unreserved, sub-delims, ':', '@', '/' and '?', plus iprivate in IRI mode.
+	QueryComponent
+	// FragmentComponent allows unreserved, sub-delims, ':', '@', '/' and '?'.
+	FragmentComponent
+)
+
+// Encode pct-encodes the UTF-8 octets of every character of s that the component does not allow.
+// ucschar characters are left as they are, so the result is valid in an IRI.
+func Encode(component Component, s string) string {
+	return encode(component, s, true)
+}
+
+// EncodeURI is like Encode but also pct-encodes ucschar and iprivate characters, so the result is
+// valid in a URI.
+func EncodeURI(component Component, s string) string {
+	return encode(component, s, false)
+}
+
+// Decode replaces every pct-encoded triple in s with the octet it encodes. It fails with
+// InvalidEncodingError for a malformed triple and InvalidUTF8Error when the result is not UTF-8.
+func Decode(s string) (string, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && (i+2 >= len(s) || !isHexDigit(rune(s[i+1])) || !isHexDigit(rune(s[i+2]))) {
+			return "", InvalidEncodingError
+		}
+	}
+	decoded := pctDecode(s)
+	if !utf8.ValidString(decoded) {
+		return "", InvalidUTF8Error
+	}
+	return decoded, nil
+}
+
+func encode(component Component, s string, iri bool) string {
+	return pctEncode(s, func(r rune) bool {
+		return component.allows(r, iri)
+	})
+}
+
+// pctEncode pct-encodes the UTF-8 octets of every character of s that allowed rejects, as well as
+// every octet that is not part of valid UTF-8.
+func pctEncode(s string, allowed func(r rune) bool) string {
+	b := strings.Builder{}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		valid := r != utf8.RuneError || size > 1
+		if valid && allowed(r) {
+			b.WriteString(s[i : i+size])
+		} else {
+			for _, c := range []byte(s[i : i+size]) {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func (c Component) allows(r rune, iri bool) bool {
+	if isUnreserved(r) || isSubDelim(r) {
+		return true
+	}
+	if iri && isUcsChar(r) {
+		return true
+	}
+	switch c {
+	case UserInfoComponent:
+		return r == ':'
+	case SegmentComponent:
+		return r == ':' || r == '@'
+	case SegmentNzNcComponent:
+		return r == '@'
+	case QueryComponent:
+		return r == ':' || r == '@' || r == '/' || r == '?' || (iri && isIPrivate(r))
+	case FragmentComponent:
+		return r == ':' || r == '@' || r == '/' || r == '?'
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	const input = "a b/c?d#e@f:g%h[i]j&k=l;m~ü\ue000"
+	type expected struct {
+		iri, uri string
+	}
+	set := map[Component]expected{
+		UserInfoComponent:    {"a%20b%2Fc%3Fd%23e%40f:g%25h%5Bi%5Dj&k=l;m~ü%EE%80%80", "a%20b%2Fc%3Fd%23e%40f:g%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+		RegNameComponent:     {"a%20b%2Fc%3Fd%23e%40f%3Ag%25h%5Bi%5Dj&k=l;m~ü%EE%80%80", "a%20b%2Fc%3Fd%23e%40f%3Ag%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+		SegmentComponent:     {"a%20b%2Fc%3Fd%23e@f:g%25h%5Bi%5Dj&k=l;m~ü%EE%80%80", "a%20b%2Fc%3Fd%23e@f:g%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+		SegmentNzNcComponent: {"a%20b%2Fc%3Fd%23e@f%3Ag%25h%5Bi%5Dj&k=l;m~ü%EE%80%80", "a%20b%2Fc%3Fd%23e@f%3Ag%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+		QueryComponent:       {"a%20b/c?d%23e@f:g%25h%5Bi%5Dj&k=l;m~ü\ue000", "a%20b/c?d%23e@f:g%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+		FragmentComponent:    {"a%20b/c?d%23e@f:g%25h%5Bi%5Dj&k=l;m~ü%EE%80%80", "a%20b/c?d%23e@f:g%25h%5Bi%5Dj&k=l;m~%C3%BC%EE%80%80"},
+	}
+
+	for c, e := range set {
+		if r := Encode(c, input); r != e.iri {
+			t.Fatalf("Encode for component %d should be '%s', got '%s'", c, e.iri, r)
+		}
+		if r := EncodeURI(c, input); r != e.uri {
+			t.Fatalf("EncodeURI for component %d should be '%s', got '%s'", c, e.uri, r)
+		}
+		for _, encoded := range []string{e.iri, e.uri} {
+			if decoded, err := Decode(encoded); err != nil || decoded != input {
+				t.Fatalf("Decode of '%s' should be '%s', got '%s' (%v)", encoded, input, decoded, err)
+			}
+		}
+	}
+
+	if r := Encode(SegmentComponent, "a\xffb"); r != "a%FFb" {
+		t.Fatalf("invalid UTF-8 should be encoded octet by octet, got '%s'", r)
+	}
+}
+
+func TestEncodedComponentsParse(t *testing.T) {
+	const input = "a b/c?d#e@f:g%h[i]j ü\ue000"
+	value := "http://" + Encode(UserInfoComponent, input) + "@" + Encode(RegNameComponent, input) +
+		"/" + Encode(SegmentComponent, input) + "?" + Encode(QueryComponent, input) + "#" + Encode(FragmentComponent, input)
+	iri, err := ParseIri(value)
+	if err != nil {
+		t.Fatalf("encoded components should parse: %s", err.Error())
+	}
+	if iri.Path != "/"+Encode(SegmentComponent, input) {
+		t.Fatalf("unexpected path '%s'", iri.Path)
+	}
+	relative, err := ParseIriReference(Encode(SegmentNzNcComponent, "a:b") + "/c")
+	if err != nil || relative.Scheme != "" {
+		t.Fatalf("encoded first segment should parse as a relative reference, got %+v (%v)", relative, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	set := map[string]error{
+		"%":         InvalidEncodingError,
+		"%2":        InvalidEncodingError,
+		"a%zzb":     InvalidEncodingError,
+		"%C3":       InvalidUTF8Error,
+		"%C3%28":    InvalidUTF8Error,
+		"%ED%A0%80": InvalidUTF8Error,
+		"%FF":       InvalidUTF8Error,
+		"%C3%A4":    nil,
+		"plain":     nil,
+	}
+
+	for v, e := range set {
+		if _, err := Decode(v); err != e {
+			t.Fatalf("Decode of '%s' should return %v, got %v", v, e, err)
+		}
+	}
+}
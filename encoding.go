@@ -0,0 +1,52 @@
+package odin_iri
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalText implements encoding.TextMarshaler and returns the original IRI value.
+func (i IRI) MarshalText() ([]byte, error) {
+	return []byte(i.Value), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by parsing text with ParseIri. An invalid IRI,
+// including empty text, returns the IriError produced by the parser. Optional values are better
+// held by a *IRI, which encoding/json leaves nil for a missing value or a JSON null.
+func (i *IRI) UnmarshalText(text []byte) error {
+	parsed, err := ParseIri(string(text))
+	if err != nil {
+		return err
+	}
+	*i = *parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler and encodes the IRI as a JSON string.
+func (i IRI) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The value must be a JSON string holding a valid IRI;
+// a JSON null leaves the IRI untouched as is the convention for encoding/json.
+func (i *IRI) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return i.UnmarshalText([]byte(value))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, which encoding/gob uses, and returns the
+// UTF-8 bytes of the IRI value.
+func (i IRI) MarshalBinary() ([]byte, error) {
+	return i.MarshalText()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler with the same validation as UnmarshalText.
+func (i *IRI) UnmarshalBinary(data []byte) error {
+	return i.UnmarshalText(data)
+}
@@ -0,0 +1,109 @@
+package odin_iri
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type encodingConfig struct {
+	Endpoint IRI  `json:"endpoint"`
+	Optional *IRI `json:"optional,omitempty"`
+}
+
+func TestJSON(t *testing.T) {
+	in := `{"endpoint":"http://exämple.org/ü?q#f","optional":"urn:isbn:0451450523"}`
+	var config encodingConfig
+	if err := json.Unmarshal([]byte(in), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint.Authority != "exämple.org" || config.Endpoint.Path != "/ü" {
+		t.Fatalf("endpoint should be parsed, got %+v", config.Endpoint)
+	}
+	if config.Endpoint.Spans().Host.Start != 7 {
+		t.Fatalf("spans should survive decoding, got %+v", config.Endpoint.Spans())
+	}
+	if config.Optional == nil || config.Optional.Scheme != "urn" {
+		t.Fatalf("optional should be parsed, got %+v", config.Optional)
+	}
+	out, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Fatalf("json should round trip as '%s', got '%s'", in, out)
+	}
+
+	config = encodingConfig{}
+	if err = json.Unmarshal([]byte(`{"endpoint":null}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint.Value != "" {
+		t.Fatalf("null should leave the zero IRI, got %+v", config.Endpoint)
+	}
+
+	failSet := []string{
+		`{"endpoint":"http://exämple.org/ bad"}`,
+		`{"endpoint":"1http://example.org"}`,
+		`{"endpoint":""}`,
+	}
+	for _, v := range failSet {
+		err = json.Unmarshal([]byte(v), &config)
+		var iriErr IriError
+		if !errors.As(err, &iriErr) {
+			t.Fatalf("decoding '%s' should fail with an IriError, got %v", v, err)
+		}
+	}
+	if err = json.Unmarshal([]byte(`{"endpoint":42}`), &config); err == nil {
+		t.Fatalf("decoding a number should fail")
+	}
+}
+
+func TestText(t *testing.T) {
+	var iri IRI
+	var unmarshaler encoding.TextUnmarshaler = &iri
+	if err := unmarshaler.UnmarshalText([]byte("ldap://[2001:db8::7]/c=GB?objectClass?one")); err != nil {
+		t.Fatal(err)
+	}
+	if iri.Host() != "[2001:db8::7]" || iri.Query != "objectClass?one" {
+		t.Fatalf("text should be parsed, got %+v", iri)
+	}
+	text, _ := iri.MarshalText()
+	if string(text) != iri.Value {
+		t.Fatalf("text should be '%s', got '%s'", iri.Value, text)
+	}
+	if err := unmarshaler.UnmarshalText([]byte("no scheme")); err == nil {
+		t.Fatalf("UnmarshalText should fail for an invalid IRI")
+	}
+	if err := unmarshaler.UnmarshalText(nil); err == nil {
+		t.Fatalf("UnmarshalText should fail for empty text")
+	}
+}
+
+func TestGob(t *testing.T) {
+	in := encodingConfig{}
+	if err := in.Endpoint.UnmarshalText([]byte("tel:+1-816-555-1212")); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out encodingConfig
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Endpoint.Value != in.Endpoint.Value || out.Endpoint.Path != "+1-816-555-1212" {
+		t.Fatalf("gob should round trip the IRI, got %+v", out.Endpoint)
+	}
+
+	var iri IRI
+	err := iri.UnmarshalBinary([]byte("http://[::1"))
+	var iriErr IriError
+	if !errors.As(err, &iriErr) {
+		t.Fatalf("UnmarshalBinary should fail with an IriError, got %v", err)
+	}
+}
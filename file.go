@@ -0,0 +1,87 @@
+package odin_iri
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// NonLocalFileError is returned by FilePath for a "file:" IRI whose host names another machine.
+var NonLocalFileError = errors.New("file iri does not refer to the local host")
+
+// FileIRIFromPath builds a "file:" IRI with an empty authority (RFC 8089) from an absolute path
+// of the local file system. Every segment is pct-encoded where needed, non-ASCII characters are
+// kept as is and bytes that are not UTF-8 are pct-encoded so the path round trips through
+// FilePath. The ':' of a first segment that looks like a DOS drive letter, as in "/C:", is
+// pct-encoded too, so that it does not become a drive.
+func FileIRIFromPath(path string) (*IRI, error) {
+	if !filepath.IsAbs(path) {
+		return nil, errors.New("file path must be absolute")
+	}
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, segment := range segments {
+		segments[i] = Encode(SegmentComponent, segment)
+	}
+	// A first segment such as "C:" would be read back by FilePath as a DOS drive letter.
+	if len(segments) > 1 && segments[0] == "" && fileDrive(segments[1]) != "" {
+		segments[1] = segments[1][:1] + "%3A"
+	}
+	return ParseIri("file://" + strings.Join(segments, "/"))
+}
+
+// FilePath returns the local path of a "file:" IRI. The authority must be empty or "localhost",
+// otherwise NonLocalFileError is returned; the "file:/path" form without an authority is also
+// accepted. A DOS drive letter, as in "file:///C:/x" or "file:C:/x", makes the path start with the
+// drive, "C:/x". Pct-encoded segments are decoded, failing for an encoded '/' or NUL that cannot
+// be part of a segment. The query and fragment are ignored.
+func (i *IRI) FilePath() (string, error) {
+	if !strings.EqualFold(i.Scheme, "file") {
+		return "", errors.New("not a file iri")
+	}
+	if i.Spans().Host.Present() {
+		if i.Spans().UserInfo.Present() || i.Spans().Port.Present() {
+			return "", errors.New("file authorities only hold a host")
+		}
+		if host := i.Host(); host != "" && !strings.EqualFold(host, "localhost") {
+			return "", NonLocalFileError
+		}
+	}
+	path := i.Path
+	if drive := fileDrive(path); drive != "" {
+		path = drive + path[strings.IndexByte(path, ':')+1:]
+		if path == drive {
+			path += "/"
+		}
+	} else if path == "" || path[0] != '/' {
+		return "", errors.New("file path must be absolute")
+	}
+	segments := strings.Split(path, "/")
+	for j, segment := range segments {
+		decoded, err := Decode(segment)
+		if err == InvalidUTF8Error {
+			// Local file names are byte strings, so octets that are not UTF-8 are kept.
+			decoded, err = pctDecode(segment), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if strings.ContainsAny(decoded, "/\x00") {
+			return "", errors.New("file path segment cannot hold '/' or NUL")
+		}
+		segments[j] = decoded
+	}
+	return filepath.FromSlash(strings.Join(segments, "/")), nil
+}
+
+// fileDrive returns the drive, as "C:", that starts a path of the form "/C:" or "C:" followed by
+// '/' or the end of the path (RFC 8089 appendix E.2).
+func fileDrive(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if len(path) < 2 || !isAlpha(rune(path[0])) || path[1] != ':' {
+		return ""
+	}
+	if len(path) > 2 && path[2] != '/' {
+		return ""
+	}
+	return path[:2]
+}
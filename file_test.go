@@ -0,0 +1,113 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func TestFileIRIFromPath(t *testing.T) {
+	set := map[string]string{
+		"/":                    "file:///",
+		"/etc/fstab":           "file:///etc/fstab",
+		"/home/user/":          "file:///home/user/",
+		"/tmp/a b/c#d?e%f":     "file:///tmp/a%20b/c%23d%3Fe%25f",
+		"/srv/Grüße/日本.txt":    "file:///srv/Grüße/日本.txt",
+		"/srv/raw\xff":         "file:///srv/raw%FF",
+		"/srv/[brackets]":      "file:///srv/%5Bbrackets%5D",
+		"/srv/x:y@z":           "file:///srv/x:y@z",
+		"/srv/back\\slash":     "file:///srv/back%5Cslash",
+		"/srv/\ue000private":   "file:///srv/%EE%80%80private",
+		"/srv/semi;colon&amp=": "file:///srv/semi;colon&amp=",
+		"/C:":                  "file:///C%3A",
+		"/c:/dir":              "file:///c%3A/dir",
+		"/C:x/y":               "file:///C:x/y",
+		"/srv/C:":              "file:///srv/C:",
+	}
+
+	for path, e := range set {
+		iri, err := FileIRIFromPath(path)
+		if err != nil {
+			t.Fatalf("FileIRIFromPath should succeed with '%s': %s", path, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("FileIRIFromPath of '%s' should be '%s', got '%s'", path, e, iri.Value)
+		}
+		back, err := iri.FilePath()
+		if err != nil {
+			t.Fatalf("FilePath should succeed with '%s': %s", iri.Value, err.Error())
+		}
+		if back != path {
+			t.Fatalf("'%s' should round trip to '%s', got '%s'", iri.Value, path, back)
+		}
+	}
+
+	failSet := []string{
+		"",
+		"relative/path",
+		"./x",
+	}
+
+	for _, path := range failSet {
+		if _, err := FileIRIFromPath(path); err == nil {
+			t.Fatalf("FileIRIFromPath should fail with '%s'", path)
+		}
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	set := map[string]string{
+		"file:///etc/fstab":                  "/etc/fstab",
+		"file://localhost/etc/fstab":         "/etc/fstab",
+		"FILE://LocalHost/etc/fstab":         "/etc/fstab",
+		"file:/etc/fstab":                    "/etc/fstab",
+		"file:///srv/%E6%97%A5%E6%9C%AC.txt": "/srv/日本.txt",
+		"file:///srv/日本.txt?query#fragment":  "/srv/日本.txt",
+		"file:///srv/a%20b/":                 "/srv/a b/",
+		"file:///C:/Windows/System32":        "C:/Windows/System32",
+		"file://localhost/c:/x":              "c:/x",
+		"file:C:/x":                          "C:/x",
+		"file:///D:":                         "D:/",
+		"file:///CD:/x":                      "/CD:/x",
+		"file:///srv/%FF":                    "/srv/\xff",
+		"file:////double":                    "//double",
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		path, err := iri.FilePath()
+		if err != nil {
+			t.Fatalf("FilePath should succeed with '%s': %s", v, err.Error())
+		}
+		if path != e {
+			t.Fatalf("FilePath of '%s' should be '%s', got '%s'", v, e, path)
+		}
+	}
+
+	failSet := []string{
+		"http://example.org/etc/fstab",
+		"file://example.org/etc/fstab",
+		"file://user@localhost/etc/fstab",
+		"file://localhost:21/etc/fstab",
+		"file:relative/path",
+		"file://localhost",
+		"file:///srv/a%2Fb",
+		"file:///srv/a%00b",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := iri.FilePath(); err == nil {
+			t.Fatalf("FilePath should fail with '%s'", v)
+		}
+	}
+
+	iri, _ := ParseIri("file://server/share/x")
+	if _, err := iri.FilePath(); err != NonLocalFileError {
+		t.Fatalf("FilePath should fail with NonLocalFileError for a remote host, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package odin_iri
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeoURI is a "geo:" IRI (RFC 5870). For the default "wgs84" reference system the coordinates
+// are latitude, longitude and altitude; other reference systems give them their own meaning.
+type GeoURI struct {
+	Latitude  float64
+	Longitude float64
+	// Altitude holds the third coordinate, when HasAltitude is set.
+	Altitude    float64
+	HasAltitude bool
+	// CRS is the coordinate reference system label as written, empty when the IRI does not name
+	// one and so uses "wgs84".
+	CRS string
+	// Uncertainty is the "u" parameter in meters, when HasUncertainty is set.
+	Uncertainty    float64
+	HasUncertainty bool
+	// Params holds the extension parameters in order with decoded values.
+	Params []GeoParam
+}
+
+// GeoParam is an extension parameter of a "geo:" IRI.
+type GeoParam struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+var (
+	geoNumber    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	geoLabelText = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// ParseGeoURI extracts the coordinates and parameters of a "geo:" IRI. The "crs" parameter may
+// only come first and the "u" parameter only first or right after "crs". WGS-84 coordinates must
+// lie within [-90, 90] and [-180, 180], while those of other reference systems are not checked.
+func ParseGeoURI(iri *IRI) (*GeoURI, error) {
+	if !strings.EqualFold(iri.Scheme, "geo") {
+		return nil, errors.New("not a geo uri")
+	}
+	if iri.Spans().Host.Present() || iri.Spans().Query.Present() || iri.Spans().Fragment.Present() {
+		return nil, errors.New("a geo uri only has a path")
+	}
+	parameters := strings.Split(iri.Path, ";")
+	coordinates := strings.Split(parameters[0], ",")
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return nil, errors.New("a geo uri has two or three coordinates")
+	}
+	values := make([]float64, len(coordinates))
+	for c, coordinate := range coordinates {
+		value, err := parseGeoNumber(coordinate)
+		if err != nil {
+			return nil, err
+		}
+		values[c] = value
+	}
+	g := &GeoURI{Latitude: values[0], Longitude: values[1]}
+	if len(values) == 3 {
+		g.Altitude, g.HasAltitude = values[2], true
+	}
+
+	for p, parameter := range parameters[1:] {
+		name, rawValue, hasValue := strings.Cut(parameter, "=")
+		if !geoLabelText.MatchString(name) {
+			return nil, errors.New("invalid geo parameter name")
+		}
+		if hasValue && !isGeoParamValue(rawValue) {
+			return nil, errors.New("invalid geo parameter value")
+		}
+		value, err := Decode(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case strings.EqualFold(name, "crs"):
+			if p != 0 || !hasValue || !geoLabelText.MatchString(rawValue) {
+				return nil, errors.New("the crs parameter must come first and hold a label")
+			}
+			g.CRS = rawValue
+		case strings.EqualFold(name, "u"):
+			if p > 1 || p == 1 && g.CRS == "" || !hasValue {
+				return nil, errors.New("the u parameter must come first or right after crs")
+			}
+			uncertainty, err := parseGeoNumber(rawValue)
+			if err != nil || uncertainty < 0 || rawValue[0] == '-' {
+				return nil, errors.New("the u parameter must be a non-negative number")
+			}
+			g.Uncertainty, g.HasUncertainty = uncertainty, true
+		default:
+			g.Params = append(g.Params, GeoParam{Name: name, Value: value, HasValue: hasValue})
+		}
+	}
+
+	if g.IsWGS84() && (math.Abs(g.Latitude) > 90 || math.Abs(g.Longitude) > 180) {
+		return nil, errors.New("wgs84 coordinates out of range")
+	}
+	return g, nil
+}
+
+// IsWGS84 reports whether the coordinates use the World Geodetic System 1984, the default.
+func (g *GeoURI) IsWGS84() bool {
+	return g.CRS == "" || strings.EqualFold(g.CRS, "wgs84")
+}
+
+// Param returns the decoded value of the first extension parameter named name, compared
+// case-insensitively.
+func (g *GeoURI) Param(name string) (string, bool) {
+	for _, param := range g.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns the "geo:" IRI, writing the numbers in their shortest form and pct-encoding
+// the parameter values where needed.
+func (g *GeoURI) String() string {
+	b := strings.Builder{}
+	b.WriteString("geo:")
+	b.WriteString(formatGeoNumber(g.Latitude))
+	b.WriteByte(',')
+	b.WriteString(formatGeoNumber(g.Longitude))
+	if g.HasAltitude {
+		b.WriteByte(',')
+		b.WriteString(formatGeoNumber(g.Altitude))
+	}
+	if g.CRS != "" {
+		b.WriteString(";crs=" + g.CRS)
+	}
+	if g.HasUncertainty {
+		b.WriteString(";u=" + formatGeoNumber(g.Uncertainty))
+	}
+	for _, param := range g.Params {
+		b.WriteString(";" + param.Name)
+		if param.HasValue {
+			b.WriteString("=" + pctEncode(param.Value, func(r rune) bool {
+				return isUnreserved(r) || isUcsChar(r) || strings.ContainsRune(":&+$", r)
+			}))
+		}
+	}
+	return b.String()
+}
+
+// IRI builds the "geo:" IRI, failing when the coordinates or parameters are invalid.
+func (g *GeoURI) IRI() (*IRI, error) {
+	iri, err := ParseIri(g.String())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseGeoURI(iri); err != nil {
+		return nil, err
+	}
+	return iri, nil
+}
+
+// Equal compares two geo IRIs following RFC 5870 section 6: coordinates and uncertainty are
+// compared as numbers, the reference system and parameter names case-insensitively and
+// parameter values case-insensitively after decoding, regardless of the parameter order. With
+// WGS-84 every longitude is the same at the poles, and the longitudes 180 and -180 are the same.
+func (g *GeoURI) Equal(other *GeoURI) bool {
+	if g.IsWGS84() != other.IsWGS84() || !g.IsWGS84() && !strings.EqualFold(g.CRS, other.CRS) {
+		return false
+	}
+	if g.Latitude != other.Latitude || g.HasAltitude != other.HasAltitude || g.Altitude != other.Altitude {
+		return false
+	}
+	if g.HasUncertainty != other.HasUncertainty || g.Uncertainty != other.Uncertainty {
+		return false
+	}
+	if g.IsWGS84() {
+		pole := math.Abs(g.Latitude) == 90
+		dateline := math.Abs(g.Longitude) == 180 && math.Abs(other.Longitude) == 180
+		if !pole && !dateline && g.Longitude != other.Longitude {
+			return false
+		}
+	} else if g.Longitude != other.Longitude {
+		return false
+	}
+	return strings.Join(g.paramKeys(), ";") == strings.Join(other.paramKeys(), ";")
+}
+
+// paramKeys returns the extension parameters in a form that is independent of case and order.
+func (g *GeoURI) paramKeys() []string {
+	keys := make([]string, len(g.Params))
+	for p, param := range g.Params {
+		keys[p] = strings.ToLower(param.Name)
+		if param.HasValue {
+			keys[p] += "=" + strings.ToLower(pctEncode(param.Value, isUnreserved))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseGeoNumber(value string) (float64, error) {
+	if !geoNumber.MatchString(value) {
+		return 0, errors.New("invalid geo number")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func formatGeoNumber(value float64) string {
+	if value == 0 {
+		// -0 is written as 0.
+		return "0"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// isGeoParamValue checks 1*paramchar, where paramchar is unreserved, pct-encoded or one of
+// "[]:&+$", extended with ucschar for IRIs.
+func isGeoParamValue(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if !isUnreserved(r) && !isUcsChar(r) && !strings.ContainsRune("[]:&+$%", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package odin_iri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGeoURI(t *testing.T) {
+	set := map[string]GeoURI{
+		"geo:13.4125,103.8667":                {Latitude: 13.4125, Longitude: 103.8667},
+		"geo:48.2010,16.3695,183":             {Latitude: 48.201, Longitude: 16.3695, Altitude: 183, HasAltitude: true},
+		"geo:13.4125,103.8667;crs=wgs84;u=35": {Latitude: 13.4125, Longitude: 103.8667, CRS: "wgs84", Uncertainty: 35, HasUncertainty: true},
+		"geo:-90,0;CRS=WGS84":                 {Latitude: -90, CRS: "WGS84"},
+		"geo:400,-500;crs=local-grid":         {Latitude: 400, Longitude: -500, CRS: "local-grid"},
+		"geo:1,2;u=0.5;flag;name=a%20b;x=%5B1:2%5D&+$": {Latitude: 1, Longitude: 2, Uncertainty: 0.5, HasUncertainty: true,
+			Params: []GeoParam{{"flag", "", false}, {"name", "a b", true}, {"x", "[1:2]&+$", true}}},
+		"GEO:0,180;name=ü": {Longitude: 180, Params: []GeoParam{{"name", "ü", true}}},
+	}
+
+	for v, e := range set {
+		g := mustParse(t, v, ParseGeoURI)
+		if !reflect.DeepEqual(*g, e) {
+			t.Fatalf("unexpected geo uri for '%s': %+v", v, g)
+		}
+	}
+
+	failSet := []string{
+		"http://example.org/",
+		"geo://example.org/1,2",
+		"geo:1",
+		"geo:1,2,3,4",
+		"geo:1,",
+		"geo:1.,2",
+		"geo:.5,2",
+		"geo:+1,2",
+		"geo:1e3,2",
+		"geo:90.1,0",
+		"geo:0,-180.5",
+		"geo:0,0;crs=wgs84;crs=wgs84",
+		"geo:0,0;x=1;crs=wgs84",
+		"geo:0,0;x=1;u=1",
+		"geo:0,0;u=1;crs=wgs84",
+		"geo:0,0;u=-1",
+		"geo:0,0;u",
+		"geo:0,0;crs",
+		"geo:0,0;crs=a%20b",
+		"geo:0,0;n_ame=1",
+		"geo:0,0;=1",
+		"geo:0,0;x=",
+		"geo:0,0;x=a@b",
+		"geo:0,0;x=%FF",
+		"geo:0,0?q",
+		"geo:0,0#f",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := ParseGeoURI(iri); err == nil {
+			t.Fatalf("ParseGeoURI should fail with '%s'", v)
+		}
+	}
+}
+
+func TestGeoURIString(t *testing.T) {
+	set := map[string]string{
+		"geo:13.4125,103.8667;u=35":                 "geo:13.4125,103.8667;u=35",
+		"geo:48.2010,16.3695,183.00;crs=wgs84":      "geo:48.201,16.3695,183;crs=wgs84",
+		"geo:-0.0,0;flag;name=a%20b%3b;x=%5B1:2%5D": "geo:0,0;flag;name=a%20b%3B;x=%5B1:2%5D",
+		"geo:1,2;name=ü":                            "geo:1,2;name=ü",
+	}
+
+	for v, e := range set {
+		g := mustParse(t, v, ParseGeoURI)
+		iri, err := g.IRI()
+		if err != nil {
+			t.Fatalf("IRI should succeed for '%s': %s", v, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("'%s' should print as '%s', got '%s'", v, e, iri.Value)
+		}
+	}
+
+	if _, err := (&GeoURI{Latitude: 91}).IRI(); err == nil {
+		t.Fatalf("IRI should fail for an out of range latitude")
+	}
+	if _, err := (&GeoURI{Params: []GeoParam{{"bad name", "", false}}}).IRI(); err == nil {
+		t.Fatalf("IRI should fail for an invalid parameter name")
+	}
+}
+
+func TestGeoURIEqual(t *testing.T) {
+	equalSet := [][2]string{
+		{"geo:90,-22.43;crs=WGS84", "geo:90,46"},
+		{"geo:-90,10", "geo:-90,-170"},
+		{"geo:22.300,-118.44", "geo:22.3,-118.4400"},
+		{"geo:66,30;u=6.500;FOo=this%2dthat", "geo:66.0,30;u=6.5;foo=this-that"},
+		{"geo:47,11;foo=blue;bar=white", "geo:47,11;bar=white;foo=blue"},
+		{"geo:22,0;bar=Blah", "geo:22,0;BAR=blah"},
+		{"geo:45,180", "geo:45,-180"},
+		{"geo:-0,0", "geo:0,-0.0"},
+		{"geo:1,2;crs=Local", "geo:1,2;crs=local"},
+	}
+	differentSet := [][2]string{
+		{"geo:1,2", "geo:1,2,0"},
+		{"geo:1,2", "geo:1,2;u=0"},
+		{"geo:1,2;u=1", "geo:1,2;u=2"},
+		{"geo:1,2", "geo:2,1"},
+		{"geo:90,1;crs=local", "geo:90,2;crs=local"},
+		{"geo:1,180;crs=local", "geo:1,-180;crs=local"},
+		{"geo:1,2;crs=local", "geo:1,2"},
+		{"geo:1,2;a=1", "geo:1,2"},
+		{"geo:1,2;a", "geo:1,2;a=" + "x"},
+		{"geo:1,2;a=1;a=1", "geo:1,2;a=1"},
+	}
+
+	for _, pair := range equalSet {
+		if !mustParse(t, pair[0], ParseGeoURI).Equal(mustParse(t, pair[1], ParseGeoURI)) {
+			t.Fatalf("'%s' and '%s' should be equal", pair[0], pair[1])
+		}
+	}
+	for _, pair := range differentSet {
+		if mustParse(t, pair[0], ParseGeoURI).Equal(mustParse(t, pair[1], ParseGeoURI)) {
+			t.Fatalf("'%s' and '%s' should differ", pair[0], pair[1])
+		}
+	}
+}
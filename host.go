@@ -0,0 +1,150 @@
+package odin_iri
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Host returns the ihost component exactly as it appears in Value, including the brackets of an
+// IP-literal. An IRI without an authority returns an empty string.
+func (i *IRI) Host() string {
+	return i.component(i.Spans().Host)
+}
+
+// Port returns the port component as it appears in Value, or an empty string when none was given.
+func (i *IRI) Port() string {
+	return i.component(i.Spans().Port)
+}
+
+// UserInfo returns the iuserinfo component as it appears in Value, without the trailing '@'.
+func (i *IRI) UserInfo() string {
+	return i.component(i.Spans().UserInfo)
+}
+
+// Zone returns the decoded zone identifier of an IPv6 literal host (RFC 6874). Zones are only
+// recognised when the IRI was parsed with WithZoneID.
+func (i *IRI) Zone() string {
+	return i.zone
+}
+
+// CanonicalHost returns the host with IPv6 literals rewritten into the RFC 5952 text form, keeping
+// any zone identifier behind its "%25" delimiter. Any other kind of host is returned unchanged.
+func (i *IRI) CanonicalHost() string {
+	if i.ipv6 == nil {
+		return i.Host()
+	}
+	return "[" + formatIPv6(*i.ipv6) + formatZone(i.zone) + "]"
+}
+
+// Addr converts an IPv4address or IPv6 literal host, including its zone, into a netip.Addr.
+func (i *IRI) Addr() (netip.Addr, error) {
+	if i.ipv6 != nil {
+		return netip.AddrFrom16(*i.ipv6).WithZone(i.zone), nil
+	}
+	addr, err := netip.ParseAddr(i.Host())
+	if err != nil || !addr.Is4() {
+		return netip.Addr{}, errors.New("host is not an ip address")
+	}
+	return addr, nil
+}
+
+// FormatHost renders an address as an ihost: IPv4 addresses in dotted form and IPv6 addresses as
+// an RFC 5952 IP-literal with the zone, if any, appended using the RFC 6874 "%25" delimiter.
+func FormatHost(addr netip.Addr) string {
+	if addr.Is4() {
+		return addr.String()
+	}
+	return "[" + formatIPv6(addr.As16()) + formatZone(addr.Zone()) + "]"
+}
+
+// formatZone encodes a zone identifier, escaping everything that is not unreserved.
+func formatZone(zone string) string {
+	if zone == "" {
+		return ""
+	}
+	b := strings.Builder{}
+	b.WriteString("%25")
+	for i := 0; i < len(zone); i++ {
+		if c := zone[i]; isUnreserved(rune(c)) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func (i *IRI) component(span Span) string {
+	if !span.Present() || span.End > len(i.Value) {
+		return ""
+	}
+	return i.Value[span.Start:span.End]
+}
+
+// formatIPv6 renders an address following RFC 5952: lowercase hexadecimal without leading zeros,
+// the longest run of two or more zero groups (leftmost on a tie) compressed to "::", and mixed
+// notation for the IPv4-mapped and well known IPv4/IPv6 translation prefixes.
+func formatIPv6(addr [16]byte) string {
+	var groups [8]uint16
+	for i := range groups {
+		groups[i] = uint16(addr[i*2])<<8 | uint16(addr[i*2+1])
+	}
+	count := 8
+	mixed := isIPv4Mapped(groups) || isIPv4Translated(groups)
+	if mixed {
+		count = 6
+	}
+
+	runStart, runLength := -1, 0
+	for i := 0; i < count; i++ {
+		if groups[i] != 0 {
+			continue
+		}
+		j := i
+		for j < count && groups[j] == 0 {
+			j++
+		}
+		if j-i > runLength && j-i > 1 {
+			runStart, runLength = i, j-i
+		}
+		i = j
+	}
+
+	b := strings.Builder{}
+	for i := 0; i < count; i++ {
+		if i == runStart {
+			b.WriteString("::")
+			i += runLength - 1
+			continue
+		}
+		if i > 0 && i != runStart+runLength {
+			b.WriteByte(':')
+		}
+		b.WriteString(strconv.FormatUint(uint64(groups[i]), 16))
+	}
+	if mixed {
+		if runStart+runLength != count {
+			b.WriteByte(':')
+		}
+		for i := 12; i < 16; i++ {
+			if i > 12 {
+				b.WriteByte('.')
+			}
+			b.WriteString(strconv.Itoa(int(addr[i])))
+		}
+	}
+	return b.String()
+}
+
+// isIPv4Mapped reports whether the address is in ::ffff:0:0/96 (RFC 4291).
+func isIPv4Mapped(groups [8]uint16) bool {
+	return groups[0] == 0 && groups[1] == 0 && groups[2] == 0 && groups[3] == 0 && groups[4] == 0 && groups[5] == 0xffff
+}
+
+// isIPv4Translated reports whether the address is in the well known 64:ff9b::/96 prefix (RFC 6052).
+func isIPv4Translated(groups [8]uint16) bool {
+	return groups[0] == 0x64 && groups[1] == 0xff9b && groups[2] == 0 && groups[3] == 0 && groups[4] == 0 && groups[5] == 0
+}
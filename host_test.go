@@ -0,0 +1,119 @@
+package odin_iri
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	set := map[string]string{
+		"http://[2001:0DB8:0000:0000:0000:ff00:0042:8329]/": "[2001:db8::ff00:42:8329]",
+		"http://[2001:db8:0:0:1:0:0:1]/":                    "[2001:db8::1:0:0:1]",
+		"http://[2001:db8:0:1:1:1:1:1]/":                    "[2001:db8:0:1:1:1:1:1]",
+		"http://[0:0:0:0:0:0:0:0]/":                         "[::]",
+		"http://[0:0:0:0:0:0:0:1]/":                         "[::1]",
+		"http://[1:0:0:0:0:0:0:0]/":                         "[1::]",
+		"http://[::ffff:192.0.2.1]/":                        "[::ffff:192.0.2.1]",
+		"http://[0:0:0:0:0:FFFF:c000:0201]/":                "[::ffff:192.0.2.1]",
+		"http://[64:ff9b::1.2.3.4]/":                        "[64:ff9b::1.2.3.4]",
+		"http://[::1.2.3.4]/":                               "[::102:304]",
+		"http://example.org/":                               "example.org",
+		"http://192.0.2.16:80/":                             "192.0.2.16",
+		"mailto:John.Doe@example.com":                       "",
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if h := iri.CanonicalHost(); h != e {
+			t.Fatalf("canonical host of '%s' should be '%s', got '%s'", v, e, h)
+		}
+	}
+}
+
+func TestHostPortUserInfo(t *testing.T) {
+	iri, err := ParseIri("http://user:pw@[::1]:8080/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iri.UserInfo() != "user:pw" {
+		t.Fatalf("userinfo should be 'user:pw', got '%s'", iri.UserInfo())
+	}
+	if iri.Host() != "[::1]" {
+		t.Fatalf("host should be '[::1]', got '%s'", iri.Host())
+	}
+	if iri.Port() != "8080" {
+		t.Fatalf("port should be '8080', got '%s'", iri.Port())
+	}
+}
+
+func TestZoneID(t *testing.T) {
+	if _, err := ParseIri("http://[fe80::1%25eth0]/"); err == nil {
+		t.Fatalf("zone identifiers should require WithZoneID")
+	}
+	failSet := []string{
+		"http://[fe80::1%25]/",
+		"http://[fe80::1%eth0]/",
+		"http://[fe80::1%25eth 0]/",
+		"http://[v7.1%25eth0]/",
+	}
+	for _, v := range failSet {
+		if _, err := ParseIri(v, WithZoneID()); err == nil {
+			t.Fatalf("ParseIri should have failed with %s", v)
+		}
+	}
+
+	set := map[string]string{
+		"http://[fe80::1%25eth0]/":             "eth0",
+		"http://[FE80:0:0:0:0:0:0:1%25en%301]": "en01",
+		"http://[fe80::1%25%C3%A4]:80/":        "ä",
+	}
+	for v, zone := range set {
+		iri, err := ParseIri(v, WithZoneID())
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if iri.Zone() != zone {
+			t.Fatalf("zone of '%s' should be '%s', got '%s'", v, zone, iri.Zone())
+		}
+		addr, err := iri.Addr()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.Zone() != zone || addr.WithZone("") != netip.MustParseAddr("fe80::1") {
+			t.Fatalf("address of '%s' should be fe80::1%%%s, got %s", v, zone, addr)
+		}
+		host := FormatHost(addr)
+		if host != iri.CanonicalHost() {
+			t.Fatalf("formatted host '%s' should match canonical host '%s'", host, iri.CanonicalHost())
+		}
+		again, err := ParseIri("http://"+host+"/", WithZoneID())
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with formatted host '%s': %s", host, err.Error())
+		}
+		if againAddr, _ := again.Addr(); againAddr != addr {
+			t.Fatalf("address should round trip as %s, got %s", addr, againAddr)
+		}
+	}
+	iri, _ := ParseIri("http://[fe80::1%25%C3%A4]/", WithZoneID())
+	if iri.CanonicalHost() != "[fe80::1%25%C3%A4]" {
+		t.Fatalf("canonical host should keep the encoded zone, got '%s'", iri.CanonicalHost())
+	}
+}
+
+func TestAddr(t *testing.T) {
+	iri, _ := ParseIri("telnet://192.0.2.16:80/")
+	addr, err := iri.Addr()
+	if err != nil || addr != netip.MustParseAddr("192.0.2.16") {
+		t.Fatalf("address should be 192.0.2.16, got %s (%v)", addr, err)
+	}
+	if FormatHost(addr) != "192.0.2.16" {
+		t.Fatalf("formatted host should be '192.0.2.16', got '%s'", FormatHost(addr))
+	}
+	iri, _ = ParseIri("http://example.org/")
+	if _, err = iri.Addr(); err == nil {
+		t.Fatalf("Addr should fail for a reg-name host")
+	}
+}
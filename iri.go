@@ -1,7 +1,6 @@
 package odin_iri
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"strconv"
@@ -20,18 +19,53 @@ var EOIError = errors.New("end of input")
 type IriError struct {
 	message string
 	index   int
+	offset  int
 	char    rune
 }
 
 func (i IriError) Error() string {
-	return fmt.Sprintf("index: %d, char: %c, message: %s", i.index, i.char, i.message)
+	return fmt.Sprintf("index: %d, offset: %d, char: %c, message: %s", i.index, i.offset, i.char, i.message)
+}
+
+// Index returns the position of the offending character counted in runes.
+func (i IriError) Index() int {
+	return i.index
+}
+
+// Offset returns the position of the offending character counted in bytes.
+func (i IriError) Offset() int {
+	return i.offset
 }
 
 // ParseIri attempts to parse a value into the IRI struct.
-func ParseIri(value string) (*IRI, error) {
-	p := newParser(value)
+func ParseIri(value string, opts ...Option) (*IRI, error) {
+	p := newParser(value, opts...)
+	p.next()
+	return p.parse(p.iri)
+}
+
+// ParseIriReference attempts to parse a value that is either an IRI or a relative reference. A
+// relative reference leaves Scheme, and possibly Authority, empty.
+func ParseIriReference(value string, opts ...Option) (*IRI, error) {
+	p := newParser(value, opts...)
 	p.next()
-	return p.parse()
+	return p.parse(p.iriReference)
+}
+
+// Option enables behaviour that goes beyond the RFC 3987 grammar.
+type Option func(*options)
+
+type options struct {
+	zoneID  bool
+	schemes *SchemeRegistry
+}
+
+// WithZoneID allows IPv6 literals to carry a zone identifier introduced by "%25" (RFC 6874),
+// such as "[fe80::1%25eth0]".
+func WithZoneID() Option {
+	return func(o *options) {
+		o.zoneID = true
+	}
 }
 
 // IRI struct containing the parsed and validated value and each of its individual parts.
@@ -43,26 +77,176 @@ type IRI struct {
 	Path      string
 	Query     string
 	Fragment  string
+
+	spans Spans
+	ipv6  *[16]byte
+	zone  string
+}
+
+// Span is a half-open byte range [Start, End) within IRI.Value. The zero Span is a component that
+// is not present in the IRI, which keeps it distinct from a present but empty component.
+type Span struct {
+	Start   int
+	End     int
+	present bool
+}
+
+// Present reports whether the component was found in the IRI.
+func (s Span) Present() bool {
+	return s.present
+}
+
+// Spans holds the location of each component within IRI.Value.
+type Spans struct {
+	Scheme   Span
+	UserInfo Span
+	Host     Span
+	Port     Span
+	Path     Span
+	Query    Span
+	Fragment Span
+}
+
+// Spans returns the byte ranges of each component within Value as recorded during parsing. An IRI
+// that did not come from the parser, such as a struct literal, has its spans found by parsing
+// Value as an IRI reference, and has no spans at all when Value cannot be parsed.
+func (i *IRI) Spans() Spans {
+	// The parser records a path for every IRI reference, even an empty one.
+	if i.spans.Path.Present() {
+		return i.spans
+	}
+	parsed, err := ParseIriReference(i.Value, WithZoneID())
+	if err != nil {
+		return Spans{}
+	}
+	return parsed.spans
+}
+
+// parts holds the components RFC 3986 section 5.3 recomposes into a reference, along with
+// whether the optional ones are present at all.
+type parts struct {
+	scheme       string
+	authority    string
+	path         string
+	query        string
+	fragment     string
+	hasAuthority bool
+	hasQuery     bool
+	hasFragment  bool
 }
 
+// parts returns the components of the IRI. Whether an empty component is present is only known
+// from Value, so when Value is missing or does not hold the fields, as for a struct literal built
+// without one, the non-empty components are the present ones.
+func (i *IRI) parts() parts {
+	p := parts{
+		scheme:       i.Scheme,
+		authority:    i.Authority,
+		path:         i.Path,
+		query:        i.Query,
+		fragment:     i.Fragment,
+		hasAuthority: i.Authority != "",
+		hasQuery:     i.Query != "",
+		hasFragment:  i.Fragment != "",
+	}
+	if spans := i.Spans(); i.heldBy(spans) {
+		p.hasAuthority = spans.Host.Present()
+		p.hasQuery = spans.Query.Present()
+		p.hasFragment = spans.Fragment.Present()
+	}
+	return p
+}
+
+// heldBy reports whether the spans locate the components of the IRI within Value.
+func (i *IRI) heldBy(spans Spans) bool {
+	if !spans.Path.Present() {
+		return false
+	}
+	all := []Span{spans.Scheme, spans.UserInfo, spans.Host, spans.Port, spans.Path, spans.Query, spans.Fragment}
+	for _, span := range all {
+		if span.Present() && (span.Start > span.End || span.End > len(i.Value)) {
+			return false
+		}
+	}
+	text := func(span Span) string {
+		if !span.Present() {
+			return ""
+		}
+		return i.Value[span.Start:span.End]
+	}
+	authority := ""
+	if spans.Host.Present() {
+		start, end := spans.Host.Start, spans.Host.End
+		if spans.UserInfo.Present() {
+			start = spans.UserInfo.Start
+		}
+		if spans.Port.Present() {
+			end = spans.Port.End
+		}
+		authority = i.Value[start:end]
+	}
+	return text(spans.Scheme) == i.Scheme && authority == i.Authority && text(spans.Path) == i.Path &&
+		text(spans.Query) == i.Query && text(spans.Fragment) == i.Fragment
+}
+
+func (p parts) String() string {
+	b := strings.Builder{}
+	if p.scheme != "" {
+		b.WriteString(p.scheme)
+		b.WriteByte(':')
+	}
+	if p.hasAuthority {
+		b.WriteString("//")
+		b.WriteString(p.authority)
+	}
+	b.WriteString(p.path)
+	if p.hasQuery {
+		b.WriteByte('?')
+		b.WriteString(p.query)
+	}
+	if p.hasFragment {
+		b.WriteByte('#')
+		b.WriteString(p.fragment)
+	}
+	return b.String()
+}
+
+// parser walks the input one rune at a time. index is always a rune index into runes, and
+// offsets maps every rune index (plus the end of input) to its byte offset in value.
 type parser struct {
+	options  options
+	value    string
 	runes    []rune
+	offsets  []int
 	index    int
 	length   int
 	instance IRI
 }
 
-func newParser(value string) *parser {
+func newParser(value string, opts ...Option) *parser {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	runes := []rune(value)
+	offsets := make([]int, 0, len(runes)+1)
+	for i := range value {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(value))
 	return &parser{
-		runes:    bytes.Runes([]byte(value)),
+		options:  o,
+		value:    value,
+		runes:    runes,
+		offsets:  offsets,
 		index:    -1,
-		length:   len(value),
+		length:   len(runes),
 		instance: IRI{},
 	}
 }
 
 func (p *parser) next() bool {
-	if p.index-1 < p.length {
+	if p.index < p.length {
 		p.index++
 		return true
 	}
@@ -70,14 +254,14 @@ func (p *parser) next() bool {
 }
 
 func (p *parser) current() (rune, error) {
-	if p.index >= p.length {
+	if p.index < 0 || p.index >= p.length {
 		return 0, EOIError
 	}
 	return p.runes[p.index], nil
 }
 
 func (p *parser) peek() (rune, error) {
-	if p.index+1 == p.length {
+	if p.index+1 >= p.length {
 		return rune(0), errors.New("end of rune set reached")
 	}
 	return p.runes[p.index+1], nil
@@ -87,11 +271,40 @@ func (p *parser) setIndex(index int) {
 	p.index = index
 }
 
-func (p *parser) parse() (*IRI, error) {
-	if iErr := p.iri(); iErr != nil {
+// offset converts a rune index into a byte offset within the original value.
+func (p *parser) offset(index int) int {
+	if index < 0 {
+		return 0
+	}
+	if index > p.length {
+		index = p.length
+	}
+	return p.offsets[index]
+}
+
+// span converts a range of rune indexes into a byte Span within the original value.
+func (p *parser) span(start, end int) Span {
+	return Span{Start: p.offset(start), End: p.offset(end), present: true}
+}
+
+// slice returns the original text between two rune indexes.
+func (p *parser) slice(start, end int) string {
+	return p.value[p.offset(start):p.offset(end)]
+}
+
+func (p *parser) parse(rule func() error) (*IRI, error) {
+	if iErr := rule(); iErr != nil {
 		return nil, iErr
 	}
-	p.instance.Value = string(p.runes)
+	if p.index < p.length {
+		return nil, newIriError(p, "Unexpected character in iri")
+	}
+	p.instance.Value = p.value
+	if p.options.schemes != nil && p.instance.Scheme != "" {
+		if err := p.options.schemes.Validate(&p.instance); err != nil {
+			return nil, err
+		}
+	}
 	return &p.instance, nil
 }
 
@@ -103,28 +316,17 @@ func (p *parser) iri() error {
 	if r != ':' {
 		return newIriError(p, "iri missing ':' after schema")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	if err := p.ihierPart(); err != nil {
-		if err == EOIError {
-			return nil
-		}
 		return err
 	}
-	r, _ = p.current()
-	if r == '?' {
-		if !p.next() {
-			return EOIError
-		}
+	if r, _ = p.current(); r == '?' {
+		p.next()
 		p.iquery()
-		return nil
-	} else if r == '#' {
-		if !p.next() {
-			return EOIError
-		}
+	}
+	if r, _ = p.current(); r == '#' {
+		p.next()
 		p.ifragment()
-		return nil
 	}
 	return nil
 }
@@ -133,56 +335,36 @@ func (p *parser) ihierPart() error {
 	preIndex := p.index
 	r, _ := p.current()
 	pr, prErr := p.peek()
-	if prErr == nil {
-		if r == '/' && pr == '/' {
-			p.next()
-			if !p.next() {
-				return EOIError
-			}
-			if authErr := p.iauthority(); authErr == nil {
-				if eErr := p.ipathAbEmpty(); eErr == nil {
-					return nil
-				}
-			}
+	if prErr == nil && r == '/' && pr == '/' {
+		p.next()
+		p.next()
+		if err := p.iauthority(); err != nil {
+			return err
 		}
+		return p.ipathAbEmpty()
 	}
-	p.index = preIndex
 	if err := p.ipathAbsolute(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
 		return nil
 	}
 	p.index = preIndex
 	if err := p.ipathRootless(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
 		return nil
 	}
 	p.index = preIndex
-	if err := p.ipathEmpty(); err != nil {
-		return newIriError(p, "Invalid irelative-part value")
-	}
-	return nil
+	return p.ipathEmpty()
 }
 
 func (p *parser) iriReference() error {
 	preIndex := p.index
 	if err := p.iri(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
 		return nil
 	}
 	p.index = preIndex
-	if err := p.irelativePart(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
-		return nil
+	p.instance = IRI{}
+	if err := p.irelativeRef(); err != nil {
+		return newIriError(p, "Invalid iri-reference value")
 	}
-	return newIriError(p, "Invalid iri-reference value")
+	return nil
 }
 
 func (p *parser) absoluteIri() error {
@@ -193,23 +375,27 @@ func (p *parser) absoluteIri() error {
 	if r != ':' {
 		return newIriError(p, "absolute-iri missing ':'")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	if err := p.ihierPart(); err != nil {
 		return err
 	}
-	r, _ = p.current()
-	if r == '?' {
-		if p.next() {
-			p.iquery()
-		}
+	if r, _ = p.current(); r == '?' {
+		p.next()
+		p.iquery()
 	}
-	r, _ = p.current()
-	if r == '#' {
-		if !p.next() {
-			return EOIError
-		}
+	return nil
+}
+
+func (p *parser) irelativeRef() error {
+	if err := p.irelativePart(); err != nil {
+		return err
+	}
+	if r, _ := p.current(); r == '?' {
+		p.next()
+		p.iquery()
+	}
+	if r, _ := p.current(); r == '#' {
+		p.next()
 		p.ifragment()
 	}
 	return nil
@@ -219,180 +405,138 @@ func (p *parser) irelativePart() error {
 	preIndex := p.index
 	r, _ := p.current()
 	pr, prErr := p.peek()
-	if prErr == nil {
-		if r == '/' && pr == '/' {
-			p.next()
-			if !p.next() {
-				return EOIError
-			}
-			if authErr := p.iauthority(); authErr == nil {
-				if eErr := p.ipathAbEmpty(); eErr == nil {
-					if !p.next() {
-						return EOIError
-					}
-					return nil
-				}
-			}
+	if prErr == nil && r == '/' && pr == '/' {
+		p.next()
+		p.next()
+		if err := p.iauthority(); err != nil {
+			return err
 		}
+		return p.ipathAbEmpty()
 	}
-	p.index = preIndex
 	if err := p.ipathAbsolute(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
 		return nil
 	}
 	p.index = preIndex
 	if err := p.ipathNoSchema(); err == nil {
-		if !p.next() {
-			return EOIError
-		}
 		return nil
 	}
 	p.index = preIndex
-	if err := p.ipathEmpty(); err != nil {
-		return newIriError(p, "Invalid irelative-part value")
-	}
-	return nil
+	return p.ipathEmpty()
 }
 
 func (p *parser) iauthority() error {
 	authStart := p.index
 	preIndex := p.index
-	if err := p.iuserInfo(); err == nil {
-		r, _ := p.current()
-		if r == '@' {
-			if !p.next() {
-				return EOIError
-			}
-		} else {
-			p.index = preIndex
-		}
+	p.iuserInfo()
+	if r, _ := p.current(); r == '@' {
+		p.instance.spans.UserInfo = p.span(preIndex, p.index)
+		p.next()
 	} else {
 		p.index = preIndex
 	}
-	p.ihost()
-	r, _ := p.current()
-	preIndex = p.index
-	if r == ':' {
-		if err := p.port(); err != nil {
-			p.index = preIndex
+	hostStart := p.index
+	if err := p.ihost(); err != nil {
+		return err
+	}
+	p.instance.spans.Host = p.span(hostStart, p.index)
+	if r, _ := p.current(); r == ':' {
+		p.next()
+		portStart := p.index
+		// port = *DIGIT, so an empty port after ':' is still valid.
+		if r, _ = p.current(); isDigit(r) {
+			if err := p.port(); err != nil {
+				return err
+			}
 		}
+		p.instance.spans.Port = p.span(portStart, p.index)
 	}
-	authRunes := p.runes[authStart:p.index]
-	p.instance.Authority = string(authRunes)
+	p.instance.Authority = p.slice(authStart, p.index)
 	return nil
 }
 
-func (p *parser) iuserInfo() error {
+func (p *parser) iuserInfo() {
 	for {
 		preIndex := p.index
 		if err := p.iunreserved(); err == nil {
-			if !p.next() {
-				return EOIError
-			}
 			continue
 		}
 		p.index = preIndex
 		if err := p.pctEncoded(); err == nil {
-			if !p.next() {
-				return EOIError
-			}
 			continue
 		}
 		p.index = preIndex
 		r, _ := p.current()
-		if isSubDelim(r) {
-			if !p.next() {
-				return EOIError
-			}
-			continue
-		}
-		if r == ':' {
-			if !p.next() {
-				return EOIError
-			}
+		if isSubDelim(r) || r == ':' {
+			p.next()
 			continue
 		}
-		return nil
+		return
 	}
 }
 
-func (p *parser) ihost() {
+func (p *parser) ihost() error {
 	preIndex := p.index
-	if err := p.ipLiteral(); err == nil {
-		return
+	if r, _ := p.current(); r == '[' {
+		return p.ipLiteral()
 	}
-	p.index = preIndex
-	if err := p.ipv4Address(); err == nil {
-		return
+	if err := p.ipv4Address(); err == nil && p.atHostEnd() {
+		return nil
 	}
 	p.index = preIndex
 	p.iregName()
+	return nil
+}
+
+// atHostEnd reports whether the current rune may legally follow an ihost.
+func (p *parser) atHostEnd() bool {
+	r, err := p.current()
+	return err != nil || r == ':' || r == '/' || r == '?' || r == '#'
 }
 
 func (p *parser) iregName() {
 	for {
 		preIndex := p.index
-		if err := p.iunreserved(); err != nil {
-			p.index = preIndex
-			if err = p.pctEncoded(); err != nil {
-				p.index = preIndex
-				r, _ := p.current()
-				if !isSubDelim(r) {
-					return
-				}
-			}
+		if err := p.iunreserved(); err == nil {
+			continue
 		}
-		if !p.next() {
+		p.index = preIndex
+		if err := p.pctEncoded(); err == nil {
+			continue
+		}
+		p.index = preIndex
+		r, _ := p.current()
+		if !isSubDelim(r) {
 			return
 		}
+		p.next()
 	}
 }
 
 func (p *parser) ipath() error {
 	pathStart := p.index
 	preIndex := p.index
-	if err := p.ipathAbEmpty(); err != nil {
-		p.index = preIndex
+	if r, _ := p.current(); r == '/' {
 		if err := p.ipathAbsolute(); err != nil {
 			p.index = preIndex
-			if err := p.ipathNoSchema(); err != nil {
-				p.index = preIndex
-				if err := p.ipathRootless(); err != nil {
-					p.index = preIndex
-					if err := p.ipathEmpty(); err != nil {
-						// Technically, the grammar shouldn't allow for this...
-						return newIriError(p, "Invalid ipath")
-					}
-				}
-			}
+			p.ipathAbEmpty()
+		}
+	} else if err := p.ipathNoSchema(); err != nil {
+		p.index = preIndex
+		if err := p.ipathRootless(); err != nil {
+			p.index = preIndex
+			p.ipathEmpty()
 		}
 	}
-	p.instance.Path = string(p.runes[pathStart:p.index])
+	p.instance.Path = p.slice(pathStart, p.index)
+	p.instance.spans.Path = p.span(pathStart, p.index)
 	return nil
 }
 
 func (p *parser) ipathAbEmpty() error {
 	startIndex := p.index
-	for {
-		r, cErr := p.current()
-		if cErr != nil {
-			break
-		}
-		if r != '/' {
-			break
-		}
-		if !p.next() {
-			return EOIError
-		}
-		i := p.index
-		p.isegment()
-		if i == p.index {
-			break
-		}
-	}
-	p.instance.Path = string(p.runes[startIndex:p.index])
+	p.isegments()
+	p.instance.Path = p.slice(startIndex, p.index)
+	p.instance.spans.Path = p.span(startIndex, p.index)
 	return nil
 }
 
@@ -402,32 +546,15 @@ func (p *parser) ipathAbsolute() error {
 	if r != '/' {
 		return newIriError(p, "ipath-absolute must start with '/'")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	preIndex := p.index
 	if err := p.isegmentNz(); err != nil {
 		p.index = preIndex
 	} else {
-		for {
-			r, _ = p.current()
-			if r != '/' {
-				break
-			}
-			if !p.next() {
-				return EOIError
-			}
-			i := p.index
-			p.isegment()
-			if i == p.index {
-				break
-			}
-		}
-	}
-	p.instance.Path = string(p.runes[startIndex:p.index])
-	if !p.next() {
-		return EOIError
+		p.isegments()
 	}
+	p.instance.Path = p.slice(startIndex, p.index)
+	p.instance.spans.Path = p.span(startIndex, p.index)
 	return nil
 }
 
@@ -436,84 +563,56 @@ func (p *parser) ipathNoSchema() error {
 	if err := p.isegmentNzNc(); err != nil {
 		return err
 	}
-	for {
-		r, _ := p.current()
-		if r != '/' {
-			break
-		}
-		if !p.next() {
-			return EOIError
-		}
-		i := p.index
-		p.isegment()
-		if i == p.index {
-			break
-		}
-	}
-	p.instance.Path = string(p.runes[startIndex:p.index])
-	if !p.next() {
-		return EOIError
-	}
+	p.isegments()
+	p.instance.Path = p.slice(startIndex, p.index)
+	p.instance.spans.Path = p.span(startIndex, p.index)
 	return nil
 }
 
 func (p *parser) ipathRootless() error {
-	preIndex := p.index
-	if nzErr := p.isegmentNz(); nzErr != nil {
-		p.index = preIndex
-		return nzErr
-	}
-	if !p.next() {
-		return EOIError
-	}
-	preIndex = p.index
-	for {
-		r, rErr := p.current()
-		if rErr != nil {
-			p.index = preIndex
-			return rErr
-		}
-		if r != '/' {
-			p.index = preIndex
-			return newIriError(p, "Invalid ipath-rootless value")
-		}
-		p.isegment()
-		if !p.next() {
-			return nil
-		}
+	startIndex := p.index
+	if err := p.isegmentNz(); err != nil {
+		return err
 	}
+	p.isegments()
+	p.instance.Path = p.slice(startIndex, p.index)
+	p.instance.spans.Path = p.span(startIndex, p.index)
+	return nil
 }
 
 func (p *parser) ipathEmpty() error {
-	if _, cErr := p.current(); cErr != nil {
-		return EOIError
-	}
+	p.instance.Path = ""
+	p.instance.spans.Path = p.span(p.index, p.index)
 	return nil
 }
 
+// isegments consumes *( "/" isegment ), the tail shared by every non-empty ipath rule.
+func (p *parser) isegments() {
+	for {
+		if r, _ := p.current(); r != '/' {
+			return
+		}
+		p.next()
+		p.isegment()
+	}
+}
+
 func (p *parser) isegment() {
 	for {
-		preIndex := p.index
-		if iErr := p.ipchar(); iErr == nil {
-			continue
+		if iErr := p.ipchar(); iErr != nil {
+			return
 		}
-		p.index = preIndex
-		return
 	}
 }
 
 func (p *parser) isegmentNz() error {
 	i := 0
-	preIndex := p.index
 	for {
-		if iErr := p.ipchar(); iErr == nil {
-			i++
-			preIndex = p.index
-			continue
+		if iErr := p.ipchar(); iErr != nil {
+			break
 		}
-		break
+		i++
 	}
-	p.index = preIndex
 	if i < 1 {
 		return newIriError(p, "Invalid isegment-nz value")
 	}
@@ -536,6 +635,7 @@ func (p *parser) isegmentNzNc() error {
 		p.index = preIndex
 		r, _ := p.current()
 		if isSubDelim(r) || r == '@' {
+			p.next()
 			i++
 			continue
 		}
@@ -569,7 +669,6 @@ func (p *parser) iquery() {
 	startIndex := p.index
 	for {
 		preIndex := p.index
-
 		if iErr := p.ipchar(); iErr == nil {
 			continue
 		}
@@ -580,12 +679,11 @@ func (p *parser) iquery() {
 		p.index = preIndex
 		r, _ := p.current()
 		if r == '/' || r == '?' {
-			if !p.next() {
-				break
-			}
+			p.next()
 			continue
 		}
-		p.instance.Query = string(p.runes[startIndex:p.index])
+		p.instance.Query = p.slice(startIndex, p.index)
+		p.instance.spans.Query = p.span(startIndex, p.index)
 		return
 	}
 }
@@ -600,45 +698,35 @@ func (p *parser) ifragment() {
 		p.index = preIndex
 		r, _ := p.current()
 		if r == '/' || r == '?' {
+			p.next()
 			continue
 		}
-		p.instance.Fragment = string(p.runes[startIndex:p.index])
+		p.instance.Fragment = p.slice(startIndex, p.index)
+		p.instance.spans.Fragment = p.span(startIndex, p.index)
 		return
 	}
 }
 
 func (p *parser) iunreserved() error {
 	//ALPHA / DIGIT / "-" / "." / "_" / "~" / ucschar
-	r, _ := p.current()
-	if isAlpha(r) || isDigit(r) || r == '-' || r == '.' || r == '_' || r == '~' {
-		if !p.next() {
-			return EOIError
-		}
+	r, err := p.current()
+	if err != nil {
+		return err
+	}
+	if isUnreserved(r) {
+		p.next()
 		return nil
 	}
 	if uErr := p.ucschar(); uErr != nil {
-		return uErr
+		return newIriError(p, "Invalid iunreserved value")
 	}
-	return newIriError(p, "Invalid iunreserved value")
+	return nil
 }
 
 func (p *parser) ucschar() error {
 	r, _ := p.current()
-	if (r >= 0xa0 && r <= 0xd7ff) ||
-		(r >= 0x10000 && r <= 0x1fffd) ||
-		(r >= 0x20000 && r <= 0x2fffd) ||
-		(r >= 0x30000 && r <= 0x3fffd) ||
-		(r >= 0x40000 && r <= 0x4fffd) ||
-		(r >= 0x50000 && r <= 0x5fffd) ||
-		(r >= 0x60000 && r <= 0x6fffd) ||
-		(r >= 0x70000 && r <= 0x7fffd) ||
-		(r >= 0x80000 && r <= 0x8fffd) ||
-		(r >= 0x90000 && r <= 0x9fffd) ||
-		(r >= 0xa0000 && r <= 0xafffd) ||
-		(r >= 0xb0000 && r <= 0xbfffd) ||
-		(r >= 0xc0000 && r <= 0xcfffd) ||
-		(r >= 0xd0000 && r <= 0xdfffd) ||
-		(r >= 0xe0000 && r <= 0xefffd) {
+	if isUcsChar(r) {
+		p.next()
 		return nil
 	}
 	return newIriError(p, fmt.Sprintf("Invalid ucschar value %c", r))
@@ -646,51 +734,43 @@ func (p *parser) ucschar() error {
 
 func (p *parser) iprivate() error {
 	r, _ := p.current()
-	if (r >= 0xe000 && r <= 0xf8ff) || (r >= 0xf0000 && r <= 0xffffd) || (r >= 0x100000 && r <= 0x10fff8) {
+	if isIPrivate(r) {
+		p.next()
 		return nil
 	}
 	return newIriError(p, fmt.Sprintf("Invalid iprivate value %c", r))
 }
 
 func (p *parser) schema() error {
-	schemaRunes := make([]rune, 0)
+	startIndex := p.index
 	r, _ := p.current()
 	if !isAlpha(r) {
 		return newIriError(p, "Scheme must start with alpha")
 	}
-	schemaRunes = append(schemaRunes, r)
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	for {
 		r, _ = p.current()
 		if isAlpha(r) || isDigit(r) || r == '+' || r == '-' || r == '.' {
-			schemaRunes = append(schemaRunes, r)
-			if !p.next() {
-				p.instance.Scheme = string(schemaRunes)
-				return nil
-			}
+			p.next()
 			continue
 		}
 		break
 	}
-	p.instance.Scheme = string(schemaRunes)
+	p.instance.Scheme = p.slice(startIndex, p.index)
+	p.instance.spans.Scheme = p.span(startIndex, p.index)
 	return nil
 }
 
 func (p *parser) port() error {
+	startIndex := p.index
 	count := 0
-	digits := make([]rune, 0)
 	for {
 		r, _ := p.current()
-		if isDigit(r) {
-			digits = append(digits, r)
-			count++
-			if p.next() {
-				continue
-			}
+		if !isDigit(r) {
+			break
 		}
-		break
+		count++
+		p.next()
 	}
 	if count == 0 {
 		return newIriError(p, "No port")
@@ -698,12 +778,10 @@ func (p *parser) port() error {
 	if count > 5 {
 		return newIriError(p, "Invalid port")
 	}
-	portStr := string(digits)
-	port, _ := strconv.Atoi(portStr)
+	port, _ := strconv.Atoi(p.slice(startIndex, p.index))
 	if port > 65535 {
 		return newIriError(p, "Invalid port value")
 	}
-	p.next()
 	return nil
 }
 
@@ -712,11 +790,15 @@ func (p *parser) ipLiteral() error {
 	if r != '[' {
 		return newIriError(p, "Missing starting '[' ip literal")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	preIndex := p.index
-	if ipv6Err := p.ipv6Address(); ipv6Err != nil {
+	if ipv6Err := p.ipv6Address(); ipv6Err == nil {
+		if r, _ = p.current(); r == '%' && p.options.zoneID {
+			if zErr := p.zoneID(); zErr != nil {
+				return zErr
+			}
+		}
+	} else {
 		p.index = preIndex
 		if ipvfErr := p.ipvFuture(); ipvfErr != nil {
 			return newIriError(p, "Invalid ipv6 or ipv future for ip literal")
@@ -730,85 +812,138 @@ func (p *parser) ipLiteral() error {
 	return nil
 }
 
+func (p *parser) zoneID() error {
+	// "%25" 1*( unreserved / pct-encoded )
+	preIndex := p.index
+	if pctErr := p.pctEncoded(); pctErr != nil || p.slice(preIndex, p.index) != "%25" {
+		p.index = preIndex
+		return newIriError(p, "Zone identifier must start with '%25'")
+	}
+	zoneStart := p.index
+	for {
+		unreservedIndex := p.index
+		if r, _ := p.current(); isUnreserved(r) {
+			p.next()
+			continue
+		}
+		if pctErr := p.pctEncoded(); pctErr == nil {
+			continue
+		}
+		p.index = unreservedIndex
+		break
+	}
+	if zoneStart == p.index {
+		return newIriError(p, "Empty zone identifier")
+	}
+	p.instance.zone = pctDecode(p.slice(zoneStart, p.index))
+	return nil
+}
+
 func (p *parser) ipvFuture() error {
 	r, _ := p.current()
 	if r != 'v' {
 		return newIriError(p, "IpvFuture must start with 'v'")
 	}
-	h16Count := 0
+	p.next()
+	hexCount := 0
 	for {
-		p.next()
-		if h16Err := p.h16(); h16Err == nil {
-			h16Count++
-			continue
-		}
-		if h16Count < 1 {
-			return newIriError(p, "Invalid IpvFuture")
+		if r, _ = p.current(); !isHexDigit(r) {
+			break
 		}
-		break
+		hexCount++
+		p.next()
 	}
-	r, _ = p.current()
-	if r != '.' {
+	if hexCount < 1 {
+		return newIriError(p, "Invalid IpvFuture")
+	}
+	if r, _ = p.current(); r != '.' {
 		return newIriError(p, "Invalid IpvFuture")
 	}
 	p.next()
 	postCount := 0
 	for {
 		r, _ = p.current()
-		if isUnreserved(r) || isSubDelim(r) || r == ':' {
-			postCount++
-			if p.next() {
-				continue
-			} else {
-				return EOIError
-			}
-		}
-		if postCount < 1 {
-			return newIriError(p, "Invalid IpvFuture")
+		if !isUnreserved(r) && !isSubDelim(r) && r != ':' {
+			break
 		}
-		break
+		postCount++
+		p.next()
+	}
+	if postCount < 1 {
+		return newIriError(p, "Invalid IpvFuture")
 	}
 	return nil
 }
 
+// ipv6Address matches all nine IPv6address alternatives of RFC 3986. Rather than trying each
+// alternative in turn it collects the h16 groups before and after an optional "::" and checks the
+// totals: without "::" there must be exactly 8 groups, with "::" at most 7. An ls32 counts as two
+// groups and may only appear as the final piece of the address.
 func (p *parser) ipv6Address() error {
-	groupCount := 0
+	head := make([]uint16, 0, 8)
+	tail := make([]uint16, 0, 8)
+	groups := &head
 	zeroCollapse := false
-	prevColon := false
+	expectGroup := false
 
-	for {
-		r, _ := p.current()
-		if r == ':' {
-			if prevColon {
-				if zeroCollapse {
-					return newIriError(p, "Ambiguous ':'")
-				}
-				zeroCollapse = true
-			} else if zeroCollapse {
-				return newIriError(p, "Invalid zero collapse in ipv6")
-			}
-			prevColon = true
-		} else {
-			prevColon = false
-			if pErr := p.h16(); pErr != nil {
-				if groupCount < 8 {
-					if zeroCollapse {
-						return nil
-					}
-					return newIriError(p, "Invalid group count in ipv6")
-				}
+	r, _ := p.current()
+	pr, _ := p.peek()
+	if r == ':' && pr == ':' {
+		p.next()
+		p.next()
+		zeroCollapse = true
+		groups = &tail
+	}
+	for len(head)+len(tail) < 8 {
+		preIndex := p.index
+		if ls32Err := p.ls32(); ls32Err == nil {
+			// Only an ls32 that ends the address is a tail, otherwise its groups are read one by one.
+			if r, _ = p.current(); r != ':' && r != '.' {
+				*groups = append(*groups, ls32Groups(p.slice(preIndex, p.index))...)
+				expectGroup = false
 				break
 			}
-			groupCount++
 		}
-		if groupCount == 8 {
+		p.index = preIndex
+		if h16Err := p.h16(); h16Err != nil {
+			p.index = preIndex
+			break
+		}
+		group, _ := strconv.ParseUint(p.slice(preIndex, p.index), 16, 16)
+		*groups = append(*groups, uint16(group))
+		expectGroup = false
+		if r, _ = p.current(); r != ':' {
 			break
 		}
-		if !p.next() {
-			return EOIError
+		if pr, _ = p.peek(); pr == ':' {
+			if zeroCollapse {
+				return newIriError(p, "Ambiguous ':'")
+			}
+			zeroCollapse = true
+			groups = &tail
+			p.next()
+			p.next()
+			continue
 		}
+		p.next()
+		expectGroup = true
 	}
-	p.next()
+	if expectGroup {
+		return newIriError(p, "Invalid group in ipv6")
+	}
+	count := len(head) + len(tail)
+	if (zeroCollapse && count > 7) || (!zeroCollapse && count != 8) {
+		return newIriError(p, "Invalid group count in ipv6")
+	}
+	var addr [16]byte
+	for i, g := range head {
+		addr[i*2], addr[i*2+1] = byte(g>>8), byte(g)
+	}
+	for i, g := range tail {
+		j := 8 - len(tail) + i
+		addr[j*2], addr[j*2+1] = byte(g>>8), byte(g)
+	}
+	p.instance.ipv6 = &addr
 	return nil
 }
 
@@ -821,16 +956,11 @@ func (p *parser) ls32() error {
 	if h16Err := p.h16(); h16Err != nil {
 		return h16Err
 	}
-	if !p.next() {
-		return EOIError
-	}
 	r, _ := p.current()
 	if r != ':' {
 		return newIriError(p, "invalid ls32 value")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	if h16Err := p.h16(); h16Err != nil {
 		return h16Err
 	}
@@ -842,20 +972,12 @@ func (p *parser) h16() error {
 	if !isHexDigit(r) {
 		return newIriError(p, "invalid h16 value")
 	}
-	hexCount := 1
-	for hexCount < 4 {
-		pv, pErr := p.peek()
-		if pErr != nil {
-			return nil
-		}
-		if isHexDigit(pv) {
-			if !p.next() {
-				return EOIError
-			}
-			hexCount++
-		} else {
+	p.next()
+	for hexCount := 1; hexCount < 4; hexCount++ {
+		if r, _ = p.current(); !isHexDigit(r) {
 			break
 		}
+		p.next()
 	}
 	return nil
 }
@@ -863,8 +985,7 @@ func (p *parser) h16() error {
 func (p *parser) ipv4Address() error {
 	octCount := 0
 	for octCount < 4 {
-		oErr := p.decOctet()
-		if oErr != nil && oErr != EOIError {
+		if oErr := p.decOctet(); oErr != nil {
 			return oErr
 		}
 		octCount++
@@ -875,71 +996,34 @@ func (p *parser) ipv4Address() error {
 		if r != '.' {
 			return newIriError(p, "invalid ipv4 address")
 		}
-		if !p.next() {
-			return EOIError
-		}
+		p.next()
 	}
 	return nil
 }
 
 func (p *parser) decOctet() error {
-	octetRunes := make([]rune, 0)
-	r, _ := p.current()
-	if !isDigit(r) {
-		return newIriError(p, "invalid decimal octet")
-	}
-	octetRunes = append(octetRunes, r)
-	peek, peekErr := p.peek()
-	if peekErr != nil || !isDigit(peek) {
-		if len(octetRunes) == 1 {
-			if !p.next() {
-				return EOIError
-			}
+	startIndex := p.index
+	count := 0
+	for count < 3 {
+		if r, _ := p.current(); !isDigit(r) {
+			break
 		}
-		return nil
+		count++
+		p.next()
 	}
-	if !p.next() {
-		return EOIError
+	if count == 0 {
+		return newIriError(p, "invalid decimal octet")
 	}
-	r, _ = p.current()
-	octetRunes = append(octetRunes, r)
-	d, _ := strconv.Atoi(string(octetRunes))
-	if d < 10 {
+	if r, _ := p.current(); isDigit(r) {
 		return newIriError(p, "invalid octet value")
 	}
-	peek, peekErr = p.peek()
-	if peekErr != nil || !isDigit(peek) {
-		if len(octetRunes) == 2 {
-			if !p.next() {
-				return EOIError
-			}
-		}
-		return nil
-	}
-	if !p.next() {
-		return EOIError
-	}
-	r, _ = p.current()
-	octetRunes = append(octetRunes, r)
-	d, _ = strconv.Atoi(string(octetRunes))
-	if d < 100 || d > 255 {
+	octet := p.slice(startIndex, p.index)
+	if len(octet) > 1 && octet[0] == '0' {
 		return newIriError(p, "invalid octet value")
 	}
-	peek, peekErr = p.peek()
-	if peekErr != nil {
-		if len(octetRunes) == 1 {
-			if !p.next() {
-				return EOIError
-			}
-		}
-		return nil
-	}
-	if isDigit(peek) {
+	if d, _ := strconv.Atoi(octet); d > 255 {
 		return newIriError(p, "invalid octet value")
 	}
-	if !p.next() {
-		return EOIError
-	}
 	return nil
 }
 
@@ -947,21 +1031,15 @@ func (p *parser) pctEncoded() error {
 	if r, _ := p.current(); r != '%' {
 		return newIriError(p, "invalid pct encoding")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	if r, _ := p.current(); !isHexDigit(r) {
 		return newIriError(p, "invalid pct encoding")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	if r, _ := p.current(); !isHexDigit(r) {
 		return newIriError(p, "invalid pct encoding")
 	}
-	if !p.next() {
-		return EOIError
-	}
+	p.next()
 	return nil
 }
 
@@ -969,11 +1047,53 @@ func newIriError(p *parser, message string) IriError {
 	r, _ := p.current()
 	return IriError{
 		index:   p.index,
+		offset:  p.offset(p.index),
 		char:    r,
 		message: message,
 	}
 }
 
+// pctDecode replaces every already validated pct-encoded triple in value with the octet it encodes.
+func pctDecode(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	b := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if octet, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(octet))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, value[i])
+	}
+	return string(b)
+}
+
+// ipv4Octets splits an already validated IPv4address into its four octets.
+func ipv4Octets(value string) [4]byte {
+	var octets [4]byte
+	for i, part := range strings.Split(value, ".") {
+		d, _ := strconv.Atoi(part)
+		octets[i] = byte(d)
+	}
+	return octets
+}
+
+// ls32Groups converts an already validated ls32 into its two 16 bit groups.
+func ls32Groups(value string) []uint16 {
+	if strings.Contains(value, ".") {
+		octets := ipv4Octets(value)
+		return []uint16{uint16(octets[0])<<8 | uint16(octets[1]), uint16(octets[2])<<8 | uint16(octets[3])}
+	}
+	parts := strings.Split(value, ":")
+	high, _ := strconv.ParseUint(parts[0], 16, 16)
+	low, _ := strconv.ParseUint(parts[1], 16, 16)
+	return []uint16{uint16(high), uint16(low)}
+}
+
 func isAlpha(r rune) bool {
 	return strings.ContainsRune(alpha, r)
 }
@@ -1001,3 +1121,27 @@ func isUnreserved(r rune) bool {
 func isHexDigit(r rune) bool {
 	return strings.ContainsRune("abcdefABCDEF", r) || isDigit(r)
 }
+
+func isUcsChar(r rune) bool {
+	return (r >= 0xa0 && r <= 0xd7ff) ||
+		(r >= 0xf900 && r <= 0xfdcf) ||
+		(r >= 0xfdf0 && r <= 0xffef) ||
+		(r >= 0x10000 && r <= 0x1fffd) ||
+		(r >= 0x20000 && r <= 0x2fffd) ||
+		(r >= 0x30000 && r <= 0x3fffd) ||
+		(r >= 0x40000 && r <= 0x4fffd) ||
+		(r >= 0x50000 && r <= 0x5fffd) ||
+		(r >= 0x60000 && r <= 0x6fffd) ||
+		(r >= 0x70000 && r <= 0x7fffd) ||
+		(r >= 0x80000 && r <= 0x8fffd) ||
+		(r >= 0x90000 && r <= 0x9fffd) ||
+		(r >= 0xa0000 && r <= 0xafffd) ||
+		(r >= 0xb0000 && r <= 0xbfffd) ||
+		(r >= 0xc0000 && r <= 0xcfffd) ||
+		(r >= 0xd0000 && r <= 0xdfffd) ||
+		(r >= 0xe1000 && r <= 0xefffd)
+}
+
+func isIPrivate(r rune) bool {
+	return (r >= 0xe000 && r <= 0xf8ff) || (r >= 0xf0000 && r <= 0xffffd) || (r >= 0x100000 && r <= 0x10fffd)
+}
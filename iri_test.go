@@ -1,6 +1,9 @@
 package odin_iri
 
 import (
+	"errors"
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -163,8 +166,37 @@ func TestLs32(t *testing.T) {
 }
 
 func TestIpv6Address(t *testing.T) {
-	failSet := []string{":0db8:0000:0000:0000:ff00:0042:8329"}
-	goodSet := []string{"2001:0db8:0000:0000:0000:ff00:0042:8329"}
+	failSet := []string{
+		":0db8:0000:0000:0000:ff00:0042:8329",
+		"2001:0db8:0000:0000:0000:ff00:0042",
+		"2001:0db8:0000:0000:0000:ff00:0042:8329:1",
+		"1::2::3",
+		"1:",
+		"1:2:3:4:5:6:7:8::",
+		"1:2:3:4:5:6:7:1.2.3.4",
+		"1:2:3:4:5:6:7::1.2.3.4",
+		"12345::",
+	}
+	// One entry for each of the nine IPv6address alternatives, with and without an IPv4 tail.
+	goodSet := []string{
+		"2001:0db8:0000:0000:0000:ff00:0042:8329",
+		"1:2:3:4:5:6:1.2.3.4",
+		"::2:3:4:5:6:7:8",
+		"::2:3:4:5:6:1.2.3.4",
+		"1::3:4:5:6:7:8",
+		"::3:4:5:6:7:8",
+		"1:2::4:5:6:7:8",
+		"1:2:3::5:6:7:8",
+		"1:2:3:4::6:7:8",
+		"1:2:3:4:5::7:8",
+		"64:ff9b::1.2.3.4",
+		"1:2:3:4:5:6::8",
+		"::8",
+		"1:2:3:4:5:6:7::",
+		"::",
+		"::ffff:192.0.2.1",
+		"::1.2.3.4",
+	}
 
 	for _, v := range failSet {
 		p := newParser(v)
@@ -180,6 +212,9 @@ func TestIpv6Address(t *testing.T) {
 		if err := p.ipv6Address(); err != nil {
 			t.Fatalf("ipv6Address should succeed with '%s': %s", v, err.Error())
 		}
+		if p.index != p.length {
+			t.Fatalf("ipv6Address should consume all of '%s', stopped at %d", v, p.index)
+		}
 	}
 }
 
@@ -205,8 +240,8 @@ func TestIpvFutures(t *testing.T) {
 }
 
 func TestIpLiteral(t *testing.T) {
-	failSet := []string{"7"}
-	goodSet := []string{"[v7.1-2]", "[2001:0db8:0000:0000:0000:ff00:0042:8329]"}
+	failSet := []string{"7", "[::1.2.3.256]", "[1:2:3:4:5:6:7]", "[ffff:1.2.3.4]"}
+	goodSet := []string{"[v7.1-2]", "[2001:0db8:0000:0000:0000:ff00:0042:8329]", "[::ffff:192.0.2.1]"}
 
 	for _, v := range failSet {
 		p := newParser(v)
@@ -385,3 +420,283 @@ func TestParseIri(t *testing.T) {
 	}
 
 }
+
+func TestIriErrorOffsets(t *testing.T) {
+	_, err := ParseIri("http://üsér@[bad")
+	var iriErr IriError
+	if !errors.As(err, &iriErr) {
+		t.Fatalf("expected IriError, got %v", err)
+	}
+	if iriErr.Index() != 13 {
+		t.Fatalf("rune index should be 13, got %d", iriErr.Index())
+	}
+	if iriErr.Offset() != 15 {
+		t.Fatalf("byte offset should be 15, got %d", iriErr.Offset())
+	}
+}
+
+func TestParseIriCharacterRanges(t *testing.T) {
+	goodSet := []string{
+		"http://example.org/\u00a0",
+		"http://example.org/\ud7ff",
+		"http://example.org/\uf900",
+		"http://example.org/\ufdcf",
+		"http://example.org/\ufdf0",
+		"http://example.org/\uffef",
+		"http://example.org/\U000e1000",
+		"http://example.org/\U000efffd",
+		"http://example.org/?\ue000",
+		"http://example.org/?\uf8ff",
+		"http://example.org/?\U000f0000",
+		"http://example.org/?\U0010fff8",
+		"http://example.org/?\U0010fffd",
+	}
+
+	for _, v := range goodSet {
+		if _, err := ParseIri(v); err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+	}
+
+	failSet := []string{
+		"http://example.org/\u009f",
+		"http://example.org/\uf8ff",
+		"http://example.org/\ufdd0",
+		"http://example.org/\ufdef",
+		"http://example.org/\ufff0",
+		"http://example.org/\U000e0000",
+		"http://example.org/\U000e0fff",
+		"http://example.org/\U000efffe",
+		"http://example.org/?\U0010fffe",
+		"http://example.org/#\ue000",
+		"http://example.org/ trailing",
+		"http://example.org/a b",
+		"http://example.org/?a b",
+		"http://example.org/#a b",
+		"http://example.org/%zz",
+		"http://example.org/<",
+	}
+
+	for _, v := range failSet {
+		if _, err := ParseIri(v); err == nil {
+			t.Fatalf("ParseIri should fail with '%s'", v)
+		}
+	}
+
+	_, err := ParseIri("http://exämple.org/ü bad")
+	var iriErr IriError
+	if !errors.As(err, &iriErr) {
+		t.Fatalf("expected IriError, got %v", err)
+	}
+	if iriErr.Index() != 20 || iriErr.Offset() != 22 {
+		t.Fatalf("trailing input should be reported at index 20 and offset 22, got %d and %d", iriErr.Index(), iriErr.Offset())
+	}
+}
+
+// Runes used to build random components; a mix of ASCII and multi-byte ucschar values.
+var randomIriRunes = []rune("abcXYZ019-._~äöüßéñ中文字日本語Ωπ한국🙂𝔘\U000E1000")
+
+func randomIriText(rnd *rand.Rand, min int, extra string) string {
+	pool := append(append([]rune{}, randomIriRunes...), []rune(extra)...)
+	b := strings.Builder{}
+	n := min + rnd.Intn(8)
+	for i := 0; i < n; i++ {
+		b.WriteRune(pool[rnd.Intn(len(pool))])
+	}
+	return b.String()
+}
+
+func TestParseIriMultiByte(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3987))
+	for i := 0; i < 2000; i++ {
+		host := randomIriText(rnd, 1, "")
+		segments := make([]string, rnd.Intn(4))
+		for s := range segments {
+			segments[s] = randomIriText(rnd, 0, ":@!$&'()*+,;=")
+		}
+		path := ""
+		if len(segments) > 0 {
+			path = "/" + strings.Join(segments, "/")
+		}
+		query := randomIriText(rnd, 0, "/?=&\ue000")
+		fragment := randomIriText(rnd, 0, "/?")
+		value := "x-scheme://" + host + path + "?" + query + "#" + fragment
+
+		iri, err := ParseIri(value)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", value, err.Error())
+		}
+		if iri.Value != value {
+			t.Fatalf("value should be '%s', got '%s'", value, iri.Value)
+		}
+		if iri.Authority != host {
+			t.Fatalf("authority should be '%s', got '%s'", host, iri.Authority)
+		}
+		if iri.Path != path {
+			t.Fatalf("path should be '%s', got '%s'", path, iri.Path)
+		}
+		if iri.Query != query {
+			t.Fatalf("query should be '%s', got '%s'", query, iri.Query)
+		}
+		if iri.Fragment != fragment {
+			t.Fatalf("fragment should be '%s', got '%s'", fragment, iri.Fragment)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	type expected struct {
+		scheme, userInfo, host, port, path, query, fragment string
+	}
+	// A '-' marks a component that should not be present.
+	set := map[string]expected{
+		"http://user:pw@exämple.org:8080/a/ü?q=1#frag": {"http", "user:pw", "exämple.org", "8080", "/a/ü", "q=1", "frag"},
+		"ldap://[2001:db8::7]/c=GB?objectClass?one":    {"ldap", "-", "[2001:db8::7]", "-", "/c=GB", "objectClass?one", "-"},
+		"mailto:John.Doe@example.com":                  {"mailto", "-", "-", "-", "John.Doe@example.com", "-", "-"},
+		"http://example.org:?#":                        {"http", "-", "example.org", "", "", "", ""},
+		"urn:isbn:0451450523":                          {"urn", "-", "-", "-", "isbn:0451450523", "-", "-"},
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		spans := iri.Spans()
+		check := func(name string, span Span, want string) {
+			if want == "-" {
+				if span.Present() {
+					t.Fatalf("%s should not be present in '%s', got %v", name, v, span)
+				}
+				return
+			}
+			if !span.Present() {
+				t.Fatalf("%s should be present in '%s'", name, v)
+			}
+			if got := iri.Value[span.Start:span.End]; got != want {
+				t.Fatalf("%s span in '%s' should be '%s', got '%s'", name, v, want, got)
+			}
+		}
+		check("scheme", spans.Scheme, e.scheme)
+		check("userinfo", spans.UserInfo, e.userInfo)
+		check("host", spans.Host, e.host)
+		check("port", spans.Port, e.port)
+		check("path", spans.Path, e.path)
+		check("query", spans.Query, e.query)
+		check("fragment", spans.Fragment, e.fragment)
+	}
+}
+
+func TestZeroAndHandBuiltSpans(t *testing.T) {
+	var zero IRI
+	spans := zero.Spans()
+	for name, span := range map[string]Span{
+		"scheme": spans.Scheme, "userinfo": spans.UserInfo, "host": spans.Host, "port": spans.Port,
+		"query": spans.Query, "fragment": spans.Fragment,
+	} {
+		if span.Present() {
+			t.Fatalf("%s should not be present in the zero IRI", name)
+		}
+	}
+	if (Span{}).Present() {
+		t.Fatalf("the zero Span should not be present")
+	}
+
+	u, err := zero.URL()
+	if err != nil {
+		t.Fatalf("URL should succeed for the zero IRI: %s", err.Error())
+	}
+	if u.String() != "" || u.User != nil {
+		t.Fatalf("the zero IRI should convert to an empty URL, got '%s'", u.String())
+	}
+
+	handBuilt := IRI{Value: "http://user@x.org:8080/a?q#f", Scheme: "http", Authority: "user@x.org:8080", Path: "/a", Query: "q", Fragment: "f"}
+	if handBuilt.Host() != "x.org" || handBuilt.Port() != "8080" || handBuilt.UserInfo() != "user" {
+		t.Fatalf("hand built IRI should expose its authority parts, got '%s', '%s', '%s'", handBuilt.Host(), handBuilt.Port(), handBuilt.UserInfo())
+	}
+	joined := handBuilt.JoinPath("b")
+	if joined == nil || joined.Value != "http://user@x.org:8080/a/b?q#f" {
+		t.Fatalf("JoinPath on a hand built IRI should give 'http://user@x.org:8080/a/b?q#f', got %v", joined)
+	}
+	ref, _ := ParseIriReference("../c")
+	resolved, err := handBuilt.ResolveReference(ref)
+	if err != nil || resolved.Value != "http://user@x.org:8080/c" {
+		t.Fatalf("ResolveReference on a hand built IRI should give 'http://user@x.org:8080/c', got %v", resolved)
+	}
+	withoutValue := &IRI{Scheme: "http", Authority: "x.org", Path: "/a"}
+	ref, _ = ParseIriReference("b")
+	if resolved, err := withoutValue.ResolveReference(ref); err != nil || resolved.Value != "http://x.org/b" {
+		t.Fatalf("ResolveReference on an IRI without Value should give 'http://x.org/b', got %v", resolved)
+	}
+	if joined := withoutValue.JoinPath("c"); joined == nil || joined.Value != "http://x.org/a/c" {
+		t.Fatalf("JoinPath on an IRI without Value should give 'http://x.org/a/c', got %v", joined)
+	}
+	stale := &IRI{Value: "http://x.org/a", Scheme: "http", Authority: "y.org", Path: "/b", Query: "q"}
+	if joined := stale.JoinPath("c"); joined == nil || joined.Value != "http://y.org/b/c?q" {
+		t.Fatalf("JoinPath on an IRI whose Value disagrees with its fields should give 'http://y.org/b/c?q', got %v", joined)
+	}
+	parsed, _ := ParseIri("http://x.org/a?#")
+	if joined := parsed.JoinPath("c"); joined == nil || joined.Value != "http://x.org/a/c?#" {
+		t.Fatalf("JoinPath should keep an empty query and fragment, got %v", joined)
+	}
+
+	u, err = handBuilt.URL()
+	if err != nil || u.Host != "x.org:8080" || u.Path != "/a" || u.RawQuery != "q" {
+		t.Fatalf("URL on a hand built IRI should keep its components, got %v", u)
+	}
+
+	invalid := IRI{Value: "not an iri", Path: "not an iri"}
+	if invalid.Spans().Path.Present() || invalid.Host() != "" {
+		t.Fatalf("an IRI whose Value cannot be parsed should have no spans")
+	}
+}
+
+func TestParseIriReference(t *testing.T) {
+	failSet := []string{"this:that/a b", "//[::1/", "a b", "%zz"}
+	goodSet := []string{
+		"http://example.org/bob#me",
+		"//example.org/path",
+		"/absolute?query",
+		"relative/ü/path#fragment",
+		"./this:that",
+		"?query",
+		"#fragment",
+		"",
+	}
+
+	for _, v := range failSet {
+		if _, err := ParseIriReference(v); err == nil {
+			t.Fatalf("ParseIriReference should have failed with %s", v)
+		}
+	}
+
+	for _, v := range goodSet {
+		iri, err := ParseIriReference(v)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", v, err.Error())
+		}
+		if iri.Value != v {
+			t.Fatalf("value should be '%s', got '%s'", v, iri.Value)
+		}
+	}
+
+	iri, _ := ParseIriReference("relative/path?q#f")
+	if iri.Scheme != "" || iri.Path != "relative/path" || iri.Query != "q" || iri.Fragment != "f" {
+		t.Fatalf("unexpected components for relative reference: %+v", *iri)
+	}
+}
+
+// mustParse parses value with ParseIri and then with a scheme-specific parser, failing the test
+// when either of them fails.
+func mustParse[T any](t *testing.T, value string, parse func(*IRI) (T, error)) T {
+	t.Helper()
+	iri, err := ParseIri(value)
+	if err != nil {
+		t.Fatalf("ParseIri should succeed with '%s': %s", value, err.Error())
+	}
+	parsed, err := parse(iri)
+	if err != nil {
+		t.Fatalf("parsing '%s' should succeed: %s", value, err.Error())
+	}
+	return parsed
+}
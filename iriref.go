@@ -0,0 +1,91 @@
+package odin_iri
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseIRIREF reads an IRIREF of N-Triples and Turtle, an IRI reference enclosed in '<' and '>'
+// whose characters may be written as \uXXXX or \UXXXXXXXX UCHAR escapes. Controls, space and the
+// characters <>"{}|^`\ are rejected when written as is, other escapes such as \n are rejected,
+// and the unescaped value must be an IRI reference.
+func ParseIRIREF(s string) (*IRI, error) {
+	if len(s) < 2 || s[0] != '<' || s[len(s)-1] != '>' {
+		return nil, errors.New("iriref must be enclosed in '<' and '>'")
+	}
+	b := strings.Builder{}
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			return nil, InvalidUTF8Error
+		case r == '\\':
+			decoded, length, err := unescapeUCHAR(body[i:])
+			if err != nil {
+				return nil, err
+			}
+			b.WriteRune(decoded)
+			i += length
+			continue
+		case isForbiddenInIRIREF(r):
+			return nil, fmt.Errorf("character %q is not allowed in an iriref", r)
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return ParseIriReference(b.String())
+}
+
+// FormatIRIREF writes the IRI as an N-Triples IRIREF. An IRI returned by the parsers of this
+// package never holds a character an IRIREF forbids, any found in a hand built IRI is written as
+// a UCHAR escape.
+func FormatIRIREF(iri *IRI) string {
+	b := strings.Builder{}
+	b.WriteByte('<')
+	for _, r := range iri.Value {
+		if isForbiddenInIRIREF(r) {
+			fmt.Fprintf(&b, "\\u%04X", r)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// unescapeUCHAR decodes the UCHAR at the start of s, returning the rune and the length of the
+// escape.
+func unescapeUCHAR(s string) (rune, int, error) {
+	length := 0
+	switch {
+	case strings.HasPrefix(s, `\u`):
+		length = 6
+	case strings.HasPrefix(s, `\U`):
+		length = 10
+	default:
+		return 0, 0, errors.New("only \\u and \\U escapes are allowed in an iriref")
+	}
+	if len(s) < length {
+		return 0, 0, errors.New("truncated uchar escape")
+	}
+	for _, c := range s[2:length] {
+		if !isHexDigit(c) {
+			return 0, 0, errors.New("invalid uchar escape")
+		}
+	}
+	value, err := strconv.ParseUint(s[2:length], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(value)) {
+		return 0, 0, errors.New("uchar escape is not a unicode scalar value")
+	}
+	return rune(value), length, nil
+}
+
+// isForbiddenInIRIREF reports whether r is excluded from the characters an IRIREF holds as is,
+// [^#x00-#x20<>"{}|^`\].
+func isForbiddenInIRIREF(r rune) bool {
+	return r <= 0x20 || strings.ContainsRune("<>\"{}|^`\\", r)
+}
@@ -0,0 +1,95 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func TestParseIRIREF(t *testing.T) {
+	set := map[string]string{
+		"<http://example.org/s>":               "http://example.org/s",
+		"<http://example.org/\\u00E9t\\u00e9>": "http://example.org/été",
+		"<http://example.org/\\U0001F600>":     "http://example.org/\U0001F600",
+		"<http://example.org/日本>":              "http://example.org/日本",
+		"<http://example.org/a%20b?q#f>":       "http://example.org/a%20b?q#f",
+		"<relative/path>":                      "relative/path",
+		"<#frag>":                              "#frag",
+		"<>":                                   "",
+		"<http://example.org/\\u0061\\u0062>":  "http://example.org/ab",
+		"<scheme:\\u0031>":                     "scheme:1",
+	}
+
+	for v, e := range set {
+		iri, err := ParseIRIREF(v)
+		if err != nil {
+			t.Fatalf("ParseIRIREF should succeed with '%s': %s", v, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("'%s' should unescape to '%s', got '%s'", v, e, iri.Value)
+		}
+	}
+
+	failSet := []string{
+		"http://example.org/",
+		"<http://example.org/",
+		"http://example.org/>",
+		"<",
+		"<http://example.org/ space>",
+		"<http://example.org/\\u0020>",
+		"<http://example.org/\ttab>",
+		"<http://example.org/<>",
+		"<http://example.org/\">",
+		"<http://example.org/{x}>",
+		"<http://example.org/a|b>",
+		"<http://example.org/a^b>",
+		"<http://example.org/a`b>",
+		"<http://example.org/a\\b>",
+		"<http://example.org/\\n>",
+		"<http://example.org/\\/>",
+		"<http://example.org/\\u00ZZ>",
+		"<http://example.org/\\u00E>",
+		"<http://example.org/\\U0000006>",
+		"<http://example.org/\\UFFFFFFFF>",
+		"<http://example.org/\\uD800>",
+		"<http://example.org/\\u003E>",
+		"<http://example.org/\xff>",
+		"<ht tp://example.org/>",
+		"<1:x>",
+	}
+
+	for _, v := range failSet {
+		if _, err := ParseIRIREF(v); err == nil {
+			t.Fatalf("ParseIRIREF should fail with '%s'", v)
+		}
+	}
+}
+
+func TestFormatIRIREF(t *testing.T) {
+	set := map[string]string{
+		"http://example.org/s":       "<http://example.org/s>",
+		"http://example.org/été":     "<http://example.org/été>",
+		"http://example.org/a%20b#f": "<http://example.org/a%20b#f>",
+		"rel":                        "<rel>",
+	}
+
+	for v, e := range set {
+		iri, err := ParseIriReference(v)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", v, err.Error())
+		}
+		formatted := FormatIRIREF(iri)
+		if formatted != e {
+			t.Fatalf("'%s' should format as '%s', got '%s'", v, e, formatted)
+		}
+		parsed, err := ParseIRIREF(formatted)
+		if err != nil {
+			t.Fatalf("'%s' should parse back: %s", formatted, err.Error())
+		}
+		if parsed.Value != v {
+			t.Fatalf("'%s' should parse back to '%s', got '%s'", formatted, v, parsed.Value)
+		}
+	}
+
+	if formatted := FormatIRIREF(&IRI{Value: "a b<>\"{}|^`\\\n"}); formatted != "<a\\u0020b\\u003C\\u003E\\u0022\\u007B\\u007D\\u007C\\u005E\\u0060\\u005C\\u000A>" {
+		t.Fatalf("forbidden characters should be escaped, got '%s'", formatted)
+	}
+}
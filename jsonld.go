@@ -0,0 +1,107 @@
+package odin_iri
+
+import (
+	"regexp"
+	"strings"
+)
+
+// JSONLDContext holds the parts of a JSON-LD 1.1 active context that IRI expansion uses, along
+// with the flags of the expansion.
+type JSONLDContext struct {
+	// Base is the base IRI, empty when there is none. A Base that is not an IRI is ignored.
+	Base string
+	// Vocab holds the vocabulary mapping when HasVocab is set. An empty or relative vocabulary is
+	// resolved against Base, as JSON-LD 1.1 context processing does for @vocab.
+	Vocab    string
+	HasVocab bool
+	// Terms holds the term definitions keyed by term.
+	Terms map[string]JSONLDTerm
+	// DocumentRelative resolves values against Base, as for @id values.
+	DocumentRelative bool
+	// VocabRelative expands values as terms and against Vocab, as for keys and @type values.
+	VocabRelative bool
+}
+
+// JSONLDTerm is the part of a JSON-LD term definition that IRI expansion uses.
+type JSONLDTerm struct {
+	// IRI is the IRI mapping, which may be a keyword. An empty IRI maps the term to null.
+	IRI string
+	// Prefix marks a term that may be used as the prefix of a compact IRI.
+	Prefix bool
+}
+
+var jsonldKeywords = map[string]bool{
+	"@base": true, "@container": true, "@context": true, "@direction": true, "@graph": true,
+	"@id": true, "@import": true, "@included": true, "@index": true, "@json": true,
+	"@language": true, "@list": true, "@nest": true, "@none": true, "@prefix": true,
+	"@propagate": true, "@protected": true, "@reverse": true, "@set": true, "@type": true,
+	"@value": true, "@version": true, "@vocab": true,
+}
+
+var jsonldKeywordForm = regexp.MustCompile(`^@[A-Za-z]+$`)
+
+// ExpandJSONLDIri applies the IRI Expansion algorithm of the JSON-LD 1.1 Processing Algorithms
+// and API, section 5.2. Keywords and blank node identifiers are returned as they are, terms and
+// compact IRIs are expanded from their definitions, and the remaining values are appended to the
+// vocabulary mapping or resolved against the base depending on the flags of ctx. It reports
+// false when the value expands to null: for a value that has the form of a keyword without being
+// one, and for a term mapped to null.
+func ExpandJSONLDIri(value string, ctx JSONLDContext) (string, bool) {
+	if jsonldKeywords[value] {
+		return value, true
+	}
+	if jsonldKeywordForm.MatchString(value) {
+		return "", false
+	}
+	if term, ok := ctx.Terms[value]; ok && (jsonldKeywords[term.IRI] || ctx.VocabRelative) {
+		return term.IRI, term.IRI != ""
+	}
+	if colon := strings.IndexByte(value, ':'); colon > 0 {
+		prefix, suffix := value[:colon], value[colon+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value, true
+		}
+		if term, ok := ctx.Terms[prefix]; ok && term.IRI != "" && term.Prefix {
+			return term.IRI + suffix, true
+		}
+		if _, err := ParseIri(value); err == nil {
+			return value, true
+		}
+	}
+	if ctx.VocabRelative && ctx.HasVocab {
+		return ctx.vocabulary() + value, true
+	}
+	if ctx.DocumentRelative {
+		return ctx.resolve(value), true
+	}
+	return value, true
+}
+
+// vocabulary returns the vocabulary mapping, resolving an empty or relative one against Base.
+func (c JSONLDContext) vocabulary() string {
+	if strings.HasPrefix(c.Vocab, "_:") {
+		return c.Vocab
+	}
+	if _, err := ParseIri(c.Vocab); err == nil {
+		return c.Vocab
+	}
+	return c.resolve(c.Vocab)
+}
+
+// resolve resolves value against Base using RFC 3986 section 5.2 without any normalization,
+// leaving value as it is when either of them cannot be parsed.
+func (c JSONLDContext) resolve(value string) string {
+	base, err := ParseIri(c.Base)
+	if err != nil {
+		return value
+	}
+	ref, err := ParseIriReference(value)
+	if err != nil {
+		return value
+	}
+	resolved, err := base.ResolveReference(ref)
+	if err != nil {
+		return value
+	}
+	return resolved.Value
+}
@@ -0,0 +1,154 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func newTestJSONLDContext() JSONLDContext {
+	return JSONLDContext{
+		Base:     "http://example.com/some/deep/directory/and/file#with-a-fragment",
+		Vocab:    "http://vocab.org/",
+		HasVocab: true,
+		Terms: map[string]JSONLDTerm{
+			"name":   {IRI: "http://xmlns.com/foaf/0.1/name"},
+			"foaf":   {IRI: "http://xmlns.com/foaf/0.1/", Prefix: true},
+			"ex":     {IRI: "http://example.org/", Prefix: true},
+			"term":   {IRI: "http://example.org/term", Prefix: false},
+			"id":     {IRI: "@id"},
+			"type":   {IRI: "@type"},
+			"null":   {IRI: ""},
+			"nullp":  {IRI: "", Prefix: true},
+			"http":   {IRI: "http://www.w3.org/2011/http#", Prefix: true},
+			"_":      {IRI: "http://example.org/underscore/", Prefix: true},
+			"ex:def": {IRI: "http://example.org/defined"},
+		},
+	}
+}
+
+func TestExpandJSONLDIriVocab(t *testing.T) {
+	set := map[string]string{
+		"@type":           "@type",
+		"@id":             "@id",
+		"@context":        "@context",
+		"name":            "http://xmlns.com/foaf/0.1/name",
+		"id":              "@id",
+		"type":            "@type",
+		"foaf:age":        "http://xmlns.com/foaf/0.1/age",
+		"ex:a/b?c#d":      "http://example.org/a/b?c#d",
+		"ex:":             "http://example.org/",
+		"ex:def":          "http://example.org/defined",
+		"term:x":          "term:x",
+		"ex://authority":  "ex://authority",
+		"http://x.org/y":  "http://x.org/y",
+		"http:Request":    "http://www.w3.org/2011/http#Request",
+		"_:b0":            "_:b0",
+		"urn:isbn:0451":   "urn:isbn:0451",
+		"relative":        "http://vocab.org/relative",
+		"../relative":     "http://vocab.org/../relative",
+		"#frag":           "http://vocab.org/#frag",
+		"@":               "http://vocab.org/@",
+		"@foo.bar":        "http://vocab.org/@foo.bar",
+		"nullp:x":         "nullp:x",
+		":colon-first":    "http://vocab.org/:colon-first",
+		"1:not-an-iri":    "http://vocab.org/1:not-an-iri",
+		"foaf":            "http://xmlns.com/foaf/0.1/",
+		"unmapped:suffix": "unmapped:suffix",
+	}
+
+	ctx := newTestJSONLDContext()
+	ctx.VocabRelative = true
+	for v, e := range set {
+		expanded, ok := ExpandJSONLDIri(v, ctx)
+		if !ok {
+			t.Fatalf("'%s' should not expand to null", v)
+		}
+		if expanded != e {
+			t.Fatalf("'%s' should expand to '%s', got '%s'", v, e, expanded)
+		}
+	}
+
+	failSet := []string{
+		"@ignoreMe",
+		"@Type",
+		"null",
+		"nullp",
+	}
+
+	for _, v := range failSet {
+		if expanded, ok := ExpandJSONLDIri(v, ctx); ok {
+			t.Fatalf("'%s' should expand to null, got '%s'", v, expanded)
+		}
+	}
+}
+
+func TestExpandJSONLDIriDocumentRelative(t *testing.T) {
+	set := map[string]string{
+		"../../relative":    "http://example.com/some/deep/relative",
+		"../":               "http://example.com/some/deep/directory/",
+		"relative":          "http://example.com/some/deep/directory/and/relative",
+		"#fragment-works":   "http://example.com/some/deep/directory/and/file#fragment-works",
+		"?query=works":      "http://example.com/some/deep/directory/and/file?query=works",
+		"":                  "http://example.com/some/deep/directory/and/file",
+		"/absolute":         "http://example.com/absolute",
+		"//other.org/x":     "http://other.org/x",
+		"./x/../y":          "http://example.com/some/deep/directory/and/y",
+		"../../../../../..": "http://example.com/",
+		"name":              "http://example.com/some/deep/directory/and/name",
+		"id":                "@id",
+		"foaf:age":          "http://xmlns.com/foaf/0.1/age",
+		"term:x":            "term:x",
+		"_:b0":              "_:b0",
+		"@":                 "http://example.com/some/deep/directory/and/@",
+		"not valid":         "not valid",
+		"null":              "http://example.com/some/deep/directory/and/null",
+	}
+
+	ctx := newTestJSONLDContext()
+	ctx.DocumentRelative = true
+	for v, e := range set {
+		expanded, ok := ExpandJSONLDIri(v, ctx)
+		if !ok {
+			t.Fatalf("'%s' should not expand to null", v)
+		}
+		if expanded != e {
+			t.Fatalf("'%s' should expand to '%s', got '%s'", v, e, expanded)
+		}
+	}
+
+	ctx.Base = ""
+	for _, v := range []string{"relative", "../x", "#frag"} {
+		if expanded, ok := ExpandJSONLDIri(v, ctx); !ok || expanded != v {
+			t.Fatalf("'%s' should stay as is without a base, got '%s'", v, expanded)
+		}
+	}
+}
+
+func TestExpandJSONLDIriVocabMapping(t *testing.T) {
+	set := []struct {
+		base, vocab, value, expected string
+	}{
+		{"http://example.com/doc", "", "term", "http://example.com/docterm"},
+		{"http://example.com/dir/doc", "ns/", "term", "http://example.com/dir/ns/term"},
+		{"http://example.com/dir/doc", "#", "term", "http://example.com/dir/doc#term"},
+		{"http://example.com/dir/doc", "_:", "term", "_:term"},
+		{"http://example.com/dir/doc", "http://vocab.org/#", "term", "http://vocab.org/#term"},
+		{"", "ns/", "term", "ns/term"},
+	}
+
+	for _, s := range set {
+		ctx := JSONLDContext{Base: s.base, Vocab: s.vocab, HasVocab: true, VocabRelative: true, DocumentRelative: true}
+		expanded, ok := ExpandJSONLDIri(s.value, ctx)
+		if !ok || expanded != s.expected {
+			t.Fatalf("'%s' with vocab '%s' should expand to '%s', got '%s'", s.value, s.vocab, s.expected, expanded)
+		}
+	}
+
+	ctx := JSONLDContext{Base: "http://example.com/dir/doc", VocabRelative: true, DocumentRelative: true}
+	if expanded, _ := ExpandJSONLDIri("term", ctx); expanded != "http://example.com/dir/term" {
+		t.Fatalf("'term' without a vocabulary should resolve against the base, got '%s'", expanded)
+	}
+	ctx.DocumentRelative = false
+	if expanded, _ := ExpandJSONLDIri("term", ctx); expanded != "term" {
+		t.Fatalf("'term' should stay as is, got '%s'", expanded)
+	}
+}
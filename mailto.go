@@ -0,0 +1,281 @@
+package odin_iri
+
+import (
+	"errors"
+	"strings"
+)
+
+// Mailto is the content of a "mailto:" IRI (RFC 6068). Addresses and header values are decoded, so
+// internationalized local parts and domains (RFC 6530) hold their Unicode characters.
+type Mailto struct {
+	// To holds the addresses of the path, in order.
+	To []string
+	// Headers holds the hfields in order, including any "to" header.
+	Headers []MailtoHeader
+}
+
+// MailtoHeader is a single hfield of a "mailto:" IRI.
+type MailtoHeader struct {
+	Name  string
+	Value string
+}
+
+// ParseMailto extracts the addresses and header fields of a "mailto:" IRI. Addresses found in the
+// path and in "to", "cc" and "bcc" headers must be valid addr-specs. Unlike form-urlencoded
+// queries, a '+' in a header stands for itself.
+func ParseMailto(iri *IRI) (*Mailto, error) {
+	if !strings.EqualFold(iri.Scheme, "mailto") {
+		return nil, errors.New("not a mailto iri")
+	}
+	if iri.Spans().Host.Present() {
+		return nil, errors.New("a mailto iri cannot have an authority")
+	}
+	m := &Mailto{}
+	to, err := parseMailtoAddresses(iri.Path)
+	if err != nil {
+		return nil, err
+	}
+	m.To = to
+	if !iri.Spans().Query.Present() {
+		return m, nil
+	}
+	for _, hfield := range strings.Split(iri.Query, "&") {
+		rawName, rawValue, ok := strings.Cut(hfield, "=")
+		if !ok {
+			return nil, errors.New("mailto header is missing '='")
+		}
+		name, err := Decode(rawName)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, errors.New("mailto header name is empty")
+		}
+		value, err := Decode(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		if isMailtoAddressHeader(name) {
+			if _, err := parseMailtoAddresses(rawValue); err != nil {
+				return nil, err
+			}
+		}
+		m.Headers = append(m.Headers, MailtoHeader{Name: name, Value: value})
+	}
+	return m, nil
+}
+
+// Header returns the value of the first header named name, compared case-insensitively.
+func (m *Mailto) Header(name string) string {
+	for _, header := range m.Headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// Subject returns the value of the "subject" header.
+func (m *Mailto) Subject() string {
+	return m.Header("subject")
+}
+
+// Body returns the value of the "body" header.
+func (m *Mailto) Body() string {
+	return m.Header("body")
+}
+
+// Cc returns the addresses of every "cc" header.
+func (m *Mailto) Cc() []string {
+	return m.headerAddresses("cc")
+}
+
+// Bcc returns the addresses of every "bcc" header.
+func (m *Mailto) Bcc() []string {
+	return m.headerAddresses("bcc")
+}
+
+// Recipients returns the addresses of the path followed by those of every "to" header.
+func (m *Mailto) Recipients() []string {
+	return append(append([]string{}, m.To...), m.headerAddresses("to")...)
+}
+
+// AddHeader appends a header field.
+func (m *Mailto) AddHeader(name, value string) {
+	m.Headers = append(m.Headers, MailtoHeader{Name: name, Value: value})
+}
+
+// headerAddresses reads the addresses of every header named name the way ParseMailto does, so
+// commas inside quoted local parts do not separate addresses. Headers that do not hold a valid
+// address list are skipped.
+func (m *Mailto) headerAddresses(name string) []string {
+	addresses := make([]string, 0)
+	for _, header := range m.Headers {
+		if !strings.EqualFold(header.Name, name) {
+			continue
+		}
+		if list, err := parseMailtoAddresses(encodeMailtoAddressList(header.Value)); err == nil {
+			addresses = append(addresses, list...)
+		}
+	}
+	return addresses
+}
+
+// String returns the "mailto:" IRI. Characters outside the RFC 6068 qchar set are pct-encoded,
+// non-ASCII characters are kept as is and ',' is escaped inside addresses, including those of
+// "to", "cc" and "bcc" headers, so it only separates them.
+func (m *Mailto) String() string {
+	b := strings.Builder{}
+	b.WriteString("mailto:")
+	for i, address := range m.To {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(encodeMailtoAddress(address))
+	}
+	for i, header := range m.Headers {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(pctEncode(header.Name, isMailtoQChar))
+		b.WriteByte('=')
+		if isMailtoAddressHeader(header.Name) {
+			b.WriteString(encodeMailtoAddressList(header.Value))
+		} else {
+			b.WriteString(pctEncode(header.Value, isMailtoQChar))
+		}
+	}
+	return b.String()
+}
+
+// IRI builds the "mailto:" IRI, failing when it holds an invalid address or header.
+func (m *Mailto) IRI() (*IRI, error) {
+	iri, err := ParseIri(m.String())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseMailto(iri); err != nil {
+		return nil, err
+	}
+	return iri, nil
+}
+
+// parseMailtoAddresses splits a pct-encoded list of addresses on ',' before decoding, so encoded
+// commas inside quoted local parts do not separate addresses.
+func parseMailtoAddresses(raw string) ([]string, error) {
+	addresses := make([]string, 0)
+	if raw == "" {
+		return addresses, nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		address, err := Decode(part)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateAddrSpec(address); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// encodeMailtoAddress pct-encodes an address, escaping ',' so it does not separate addresses.
+func encodeMailtoAddress(address string) string {
+	return pctEncode(address, func(r rune) bool {
+		return r != ',' && isMailtoQChar(r)
+	})
+}
+
+// encodeMailtoAddressList pct-encodes a decoded list of addresses, keeping the commas that
+// separate them and escaping those inside quoted strings.
+func encodeMailtoAddressList(value string) string {
+	addresses := make([]string, 0)
+	quoted, start := false, 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				addresses = append(addresses, encodeMailtoAddress(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	addresses = append(addresses, encodeMailtoAddress(value[start:]))
+	return strings.Join(addresses, ",")
+}
+
+// validateAddrSpec checks local-part "@" domain, where the local part is a dot-atom or a quoted
+// string and the domain a dot-atom or a domain literal. Non-ASCII characters are accepted in
+// atoms following RFC 6532.
+func validateAddrSpec(address string) error {
+	at := strings.LastIndexByte(address, '@')
+	if at < 0 {
+		return errors.New("mailto address is missing '@'")
+	}
+	local, domain := address[:at], address[at+1:]
+	if len(local) >= 2 && local[0] == '"' && local[len(local)-1] == '"' {
+		if !isQuotedStringContent(local[1 : len(local)-1]) {
+			return errors.New("invalid quoted local part")
+		}
+	} else if !isDotAtom(local) {
+		return errors.New("invalid address local part")
+	}
+	if len(domain) >= 2 && domain[0] == '[' && domain[len(domain)-1] == ']' {
+		if strings.ContainsAny(domain[1:len(domain)-1], "[]\\ ") {
+			return errors.New("invalid address domain literal")
+		}
+	} else if !isDotAtom(domain) {
+		return errors.New("invalid address domain")
+	}
+	return nil
+}
+
+// isDotAtom reports whether value is one or more atoms separated by single dots.
+func isDotAtom(value string) bool {
+	for _, atom := range strings.Split(value, ".") {
+		if atom == "" {
+			return false
+		}
+		for _, r := range atom {
+			if r < 0x80 && !isAlpha(r) && !isDigit(r) && !strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isQuotedStringContent checks the characters between the quotes of a quoted-string, where '"'
+// and '\' must be escaped by a '\'.
+func isQuotedStringContent(value string) bool {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			if i++; i == len(value) {
+				return false
+			}
+		case c == '"' || c < ' ' || c == 0x7f:
+			return false
+		}
+	}
+	return true
+}
+
+func isMailtoAddressHeader(name string) bool {
+	return strings.EqualFold(name, "to") || strings.EqualFold(name, "cc") || strings.EqualFold(name, "bcc")
+}
+
+// isMailtoQChar reports whether r may appear unencoded in a "mailto:" IRI: unreserved, ucschar or
+// one of the RFC 6068 some-delims.
+func isMailtoQChar(r rune) bool {
+	return isUnreserved(r) || isUcsChar(r) || strings.ContainsRune("!$'()*+,;:@", r)
+}
@@ -0,0 +1,116 @@
+package odin_iri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMailto(t *testing.T) {
+	type expected struct {
+		to      []string
+		subject string
+		body    string
+		cc      []string
+	}
+	set := map[string]expected{
+		"mailto:chris@example.com":                                 {[]string{"chris@example.com"}, "", "", []string{}},
+		"mailto:infobot@example.com?subject=current-issue":         {[]string{"infobot@example.com"}, "current-issue", "", []string{}},
+		"mailto:list@example.org?body=send%20current-issue%0D%0Aa": {[]string{"list@example.org"}, "", "send current-issue\r\na", []string{}},
+		"mailto:a@example.org,b@example.org?cc=c@example.org,d@example.org&subject=1+1": {
+			[]string{"a@example.org", "b@example.org"}, "1+1", "", []string{"c@example.org", "d@example.org"}},
+		"mailto:%22not%40me%22@example.org":                     {[]string{`"not@me"@example.org`}, "", "", []string{}},
+		"mailto:%22oh%5C%5Cno%22@example.org":                   {[]string{`"oh\\no"@example.org`}, "", "", []string{}},
+		"mailto:user@%E7%B4%8D%E8%B1%86.example.org":            {[]string{"user@納豆.example.org"}, "", "", []string{}},
+		"mailto:ユーザー@例え.テスト?subject=こんにちは":                      {[]string{"ユーザー@例え.テスト"}, "こんにちは", "", []string{}},
+		"mailto:?to=joe@example.com&subject=%E3%81%AF%E3%81%98": {[]string{}, "はじ", "", []string{}},
+		"mailto:user@%5B192.0.2.1%5D":                           {[]string{"user@[192.0.2.1]"}, "", "", []string{}},
+	}
+
+	for v, e := range set {
+		m := mustParse(t, v, ParseMailto)
+		if !reflect.DeepEqual(m.To, e.to) || m.Subject() != e.subject || m.Body() != e.body || !reflect.DeepEqual(m.Cc(), e.cc) {
+			t.Fatalf("unexpected mailto for '%s': %+v", v, m)
+		}
+	}
+
+	m := mustParse(t, "mailto:a@example.org?to=b@example.org,c@example.org&bcc=d@example.org", ParseMailto)
+	if r := m.Recipients(); !reflect.DeepEqual(r, []string{"a@example.org", "b@example.org", "c@example.org"}) {
+		t.Fatalf("unexpected recipients %v", r)
+	}
+	if b := m.Bcc(); !reflect.DeepEqual(b, []string{"d@example.org"}) {
+		t.Fatalf("unexpected bcc %v", b)
+	}
+
+	m = mustParse(t, "mailto:?cc=%22c%2Cd%22@x.org", ParseMailto)
+	if c := m.Cc(); !reflect.DeepEqual(c, []string{`"c,d"@x.org`}) {
+		t.Fatalf("unexpected cc %v", c)
+	}
+
+	failSet := []string{
+		"http://example.org/",
+		"mailto://example.org/a@example.org",
+		"mailto:example.org",
+		"mailto:a..b@example.org",
+		"mailto:@example.org",
+		"mailto:a@",
+		"mailto:a@example.org,",
+		"mailto:a@example.org?subject",
+		"mailto:a@example.org?=x",
+		"mailto:a@example.org?cc=nobody",
+		"mailto:a@example.org?subject=%FF",
+		"mailto:%22a%22b%22@example.org",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := ParseMailto(iri); err == nil {
+			t.Fatalf("ParseMailto should fail with '%s'", v)
+		}
+	}
+}
+
+func TestMailtoIRI(t *testing.T) {
+	set := map[string]*Mailto{
+		"mailto:a@example.org":                                  {To: []string{"a@example.org"}},
+		"mailto:a@example.org,b@example.org?subject=hello":      {To: []string{"a@example.org", "b@example.org"}, Headers: []MailtoHeader{{"subject", "hello"}}},
+		"mailto:%22a%2Cb%22@example.org":                        {To: []string{`"a,b"@example.org`}},
+		"mailto:a@example.org?cc=%22c%2Cd%22@x.org,e@x.org":     {To: []string{"a@example.org"}, Headers: []MailtoHeader{{"cc", `"c,d"@x.org,e@x.org`}}},
+		"mailto:?to=a@example.org&body=a%20b%0D%0A%26%3D%3F%25": {Headers: []MailtoHeader{{"to", "a@example.org"}, {"body", "a b\r\n&=?%"}}},
+		"mailto:ユーザー@例え.テスト?subject=1+1%23こんにちは":                {To: []string{"ユーザー@例え.テスト"}, Headers: []MailtoHeader{{"subject", "1+1#こんにちは"}}},
+	}
+
+	for e, m := range set {
+		iri, err := m.IRI()
+		if err != nil {
+			t.Fatalf("IRI should succeed for %+v: %s", m, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("IRI should be '%s', got '%s'", e, iri.Value)
+		}
+		parsed, err := ParseMailto(iri)
+		if err != nil {
+			t.Fatalf("ParseMailto should succeed with '%s': %s", iri.Value, err.Error())
+		}
+		if len(parsed.To)+len(m.To) > 0 && !reflect.DeepEqual(parsed.To, m.To) || !reflect.DeepEqual(parsed.Headers, m.Headers) {
+			t.Fatalf("'%s' should round trip to %+v, got %+v", iri.Value, m, parsed)
+		}
+		if !reflect.DeepEqual(parsed.Cc(), m.Cc()) {
+			t.Fatalf("'%s' should have the cc addresses %v, got %v", iri.Value, m.Cc(), parsed.Cc())
+		}
+	}
+
+	failSet := []*Mailto{
+		{To: []string{"nobody"}},
+		{To: []string{"a@example.org"}, Headers: []MailtoHeader{{"", "x"}}},
+		{To: []string{"a@example.org"}, Headers: []MailtoHeader{{"cc", "nobody"}}},
+	}
+
+	for _, m := range failSet {
+		if _, err := m.IRI(); err == nil {
+			t.Fatalf("IRI should fail for %+v", m)
+		}
+	}
+}
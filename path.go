@@ -0,0 +1,179 @@
+package odin_iri
+
+import (
+	"errors"
+	"strings"
+)
+
+// RawSegments returns the isegments of the path exactly as they appear in the IRI. The leading
+// '/' of an absolute path does not produce a segment, while a trailing '/' produces a final empty
+// segment, so "/a/b/" gives ["a", "b", ""]. An empty path has no segments.
+func (i *IRI) RawSegments() []string {
+	if i.Path == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimPrefix(i.Path, "/"), "/")
+}
+
+// Segments returns the path segments with their pct-encoded triples decoded. A decoded segment may
+// contain '/', which is why the segments are returned separately rather than joined.
+func (i *IRI) Segments() []string {
+	segments := i.RawSegments()
+	for s, segment := range segments {
+		segments[s] = pctDecode(segment)
+	}
+	return segments
+}
+
+// Dir returns the path up to and including its last '/', still pct-encoded, which is the base
+// used by RFC 3986 reference resolution. A path without any '/' has an empty Dir.
+func (i *IRI) Dir() string {
+	return i.Path[:strings.LastIndex(i.Path, "/")+1]
+}
+
+// Base returns the decoded last segment of the path. Unlike path.Base a trailing '/' is not
+// skipped, it marks an empty last segment and so results in an empty Base.
+func (i *IRI) Base() string {
+	return pctDecode(i.Path[strings.LastIndex(i.Path, "/")+1:])
+}
+
+// Ext returns the extension of Base, starting at its final '.', or an empty string when there is
+// none. Pct-encoded dots are decoded before the extension is found.
+func (i *IRI) Ext() string {
+	base := i.Base()
+	if dot := strings.LastIndex(base, "."); dot >= 0 {
+		return base[dot:]
+	}
+	return ""
+}
+
+// JoinPath returns a new IRI with the given decoded segments appended to the path. Each segment is
+// pct-encoded as an isegment, so '/', '?', '#' and '%' inside a segment are escaped, and the result
+// has its dot-segments removed in the same way as url.URL.JoinPath.
+func (i *IRI) JoinPath(segments ...string) *IRI {
+	p := i.parts()
+	path := p.path
+	if len(segments) > 0 {
+		// The first segment of a relative-path reference may not contain ':'.
+		escapeColon := p.scheme == "" && !p.hasAuthority && path == ""
+		if path != "" && !strings.HasSuffix(path, "/") {
+			path += "/"
+		} else if path == "" && p.hasAuthority {
+			path = "/"
+		}
+		for s, segment := range segments {
+			if s > 0 {
+				path += "/"
+			}
+			if escapeColon && s == 0 {
+				path += Encode(SegmentNzNcComponent, segment)
+			} else {
+				path += Encode(SegmentComponent, segment)
+			}
+		}
+	}
+	path = RemoveDotSegments(path)
+	if !p.hasAuthority && strings.HasPrefix(path, "//") {
+		// Without an authority a leading "//" would be read back as one, RFC 3986 section 5.3.
+		path = "/." + path
+	}
+
+	p.path = path
+	joined, err := ParseIriReference(p.String(), WithZoneID())
+	if err != nil {
+		return nil
+	}
+	return joined
+}
+
+// ResolveReference resolves ref against the IRI following the strict algorithm of RFC 3986
+// section 5.2.2, the IRI being the base. The base must have a scheme, so that the result is always
+// an IRI rather than a relative reference.
+func (i *IRI) ResolveReference(ref *IRI) (*IRI, error) {
+	if i.Scheme == "" {
+		return nil, errors.New("the base iri must have a scheme")
+	}
+	target := resolveParts(i.parts(), ref.parts())
+	return ParseIri(target.String(), WithZoneID())
+}
+
+// resolveParts computes the target of a reference from the components of the base and of the
+// reference, as in RFC 3986 section 5.2.2.
+func resolveParts(base, ref parts) parts {
+	target := ref
+	switch {
+	case ref.scheme != "":
+		target.path = RemoveDotSegments(ref.path)
+	case ref.hasAuthority:
+		target.scheme = base.scheme
+		target.path = RemoveDotSegments(ref.path)
+	default:
+		target.scheme, target.authority, target.hasAuthority = base.scheme, base.authority, base.hasAuthority
+		switch {
+		case ref.path == "":
+			target.path = base.path
+			if !ref.hasQuery {
+				target.query, target.hasQuery = base.query, base.hasQuery
+			}
+		case ref.path[0] == '/':
+			target.path = RemoveDotSegments(ref.path)
+		default:
+			target.path = RemoveDotSegments(mergePaths(base, ref.path))
+		}
+	}
+	if !target.hasAuthority && strings.HasPrefix(target.path, "//") {
+		// Without an authority a leading "//" would be read back as one, RFC 3986 section 5.3.
+		target.path = "/." + target.path
+	}
+	return target
+}
+
+// mergePaths appends a relative-path reference to the base path, RFC 3986 section 5.2.3.
+func mergePaths(base parts, path string) string {
+	if base.hasAuthority && base.path == "" {
+		return "/" + path
+	}
+	return base.path[:strings.LastIndex(base.path, "/")+1] + path
+}
+
+// RemoveDotSegments removes the "." and ".." segments from a path following the algorithm of
+// RFC 3986 section 5.2.4.
+func RemoveDotSegments(path string) string {
+	input := path
+	output := make([]string, 0)
+	for input != "" {
+		switch {
+		case strings.HasPrefix(input, "../"):
+			input = input[3:]
+		case strings.HasPrefix(input, "./"):
+			input = input[2:]
+		case strings.HasPrefix(input, "/./"):
+			input = input[2:]
+		case input == "/.":
+			input = "/"
+		case strings.HasPrefix(input, "/../"):
+			input = input[3:]
+			output = removeLastSegment(output)
+		case input == "/..":
+			input = "/"
+			output = removeLastSegment(output)
+		case input == "." || input == "..":
+			input = ""
+		default:
+			end := strings.Index(input[1:], "/") + 1
+			if end == 0 {
+				end = len(input)
+			}
+			output = append(output, input[:end])
+			input = input[end:]
+		}
+	}
+	return strings.Join(output, "")
+}
+
+func removeLastSegment(output []string) []string {
+	if len(output) == 0 {
+		return output
+	}
+	return output[:len(output)-1]
+}
@@ -0,0 +1,222 @@
+package odin_iri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	type expected struct {
+		raw, decoded []string
+	}
+	set := map[string]expected{
+		"http://example.org":            {[]string{}, []string{}},
+		"http://example.org/":           {[]string{""}, []string{""}},
+		"http://example.org/a/b%2Fc/ü/": {[]string{"a", "b%2Fc", "ü", ""}, []string{"a", "b/c", "ü", ""}},
+		"urn:isbn:0451450523":           {[]string{"isbn:0451450523"}, []string{"isbn:0451450523"}},
+		"file:///a//b":                  {[]string{"a", "", "b"}, []string{"a", "", "b"}},
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw := iri.RawSegments(); !reflect.DeepEqual(raw, e.raw) {
+			t.Fatalf("raw segments of '%s' should be %q, got %q", v, e.raw, raw)
+		}
+		if decoded := iri.Segments(); !reflect.DeepEqual(decoded, e.decoded) {
+			t.Fatalf("segments of '%s' should be %q, got %q", v, e.decoded, decoded)
+		}
+	}
+}
+
+func TestDirBaseExt(t *testing.T) {
+	type expected struct {
+		dir, base, ext string
+	}
+	set := map[string]expected{
+		"http://example.org":                 {"", "", ""},
+		"http://example.org/":                {"/", "", ""},
+		"http://example.org/a/b.txt":         {"/a/", "b.txt", ".txt"},
+		"http://example.org/a/b/":            {"/a/b/", "", ""},
+		"http://example.org/a/dir%2Fb%2Etar": {"/a/", "dir/b.tar", ".tar"},
+		"http://example.org/a/ärger.tar.gz":  {"/a/", "ärger.tar.gz", ".gz"},
+		"http://example.org/.profile":        {"/", ".profile", ".profile"},
+		"urn:isbn:0451450523":                {"", "isbn:0451450523", ""},
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if iri.Dir() != e.dir || iri.Base() != e.base || iri.Ext() != e.ext {
+			t.Fatalf("dir, base, ext of '%s' should be %q %q %q, got %q %q %q", v, e.dir, e.base, e.ext, iri.Dir(), iri.Base(), iri.Ext())
+		}
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	type join struct {
+		iri      string
+		segments []string
+		expected string
+	}
+	set := []join{
+		{"http://example.org", []string{"a", "b"}, "http://example.org/a/b"},
+		{"http://example.org/a/?q#f", []string{"b c", "d/e", "ü"}, "http://example.org/a/b%20c/d%2Fe/ü?q#f"},
+		{"http://example.org/a/b", []string{"..", "c"}, "http://example.org/a/c"},
+		{"http://example.org/a", []string{"%", "?#", "x:y@z"}, "http://example.org/a/%25/%3F%23/x:y@z"},
+		{"http://example.org/a", []string{"b", ""}, "http://example.org/a/b/"},
+		{"x:", []string{"", "", "y"}, "x:/.//y"},
+		{"", []string{"a:b", "c:d"}, "a%3Ab/c:d"},
+		{"http://example.org/a/b", []string{}, "http://example.org/a/b"},
+		{"http://example.org/a/./b/../c", []string{}, "http://example.org/a/c"},
+	}
+
+	for _, v := range set {
+		iri, err := ParseIriReference(v.iri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		joined := iri.JoinPath(v.segments...)
+		if joined == nil {
+			t.Fatalf("JoinPath of '%s' with %q should succeed", v.iri, v.segments)
+		}
+		if joined.Value != v.expected {
+			t.Fatalf("JoinPath of '%s' with %q should be '%s', got '%s'", v.iri, v.segments, v.expected, joined.Value)
+		}
+		if iri.Value != v.iri {
+			t.Fatalf("JoinPath should not modify the original IRI")
+		}
+	}
+}
+
+func TestRemoveDotSegments(t *testing.T) {
+	// Examples from RFC 3986 section 5.2.4 and 5.4.
+	set := map[string]string{
+		"/a/b/c/./../../g":   "/a/g",
+		"mid/content=5/../6": "mid/6",
+		"/b/c/./g":           "/b/c/g",
+		"/b/c/../g":          "/b/g",
+		"/b/c/../../../g":    "/g",
+		"/./g":               "/g",
+		"/../g":              "/g",
+		"/b/c/g.":            "/b/c/g.",
+		"/b/c/.g":            "/b/c/.g",
+		"/b/c/g..":           "/b/c/g..",
+		"/b/c/..g":           "/b/c/..g",
+		"/b/c/./../g":        "/b/g",
+		"/b/c/./g/.":         "/b/c/g/",
+		"/b/c/g/./h":         "/b/c/g/h",
+		"/b/c/g/../h":        "/b/c/h",
+		"/b/c/..":            "/b/",
+		"/b/c/.":             "/b/c/",
+		".":                  "",
+		"..":                 "",
+		"../a":               "a",
+		"./a/..":             "/",
+		"":                   "",
+	}
+
+	for v, e := range set {
+		if r := RemoveDotSegments(v); r != e {
+			t.Fatalf("RemoveDotSegments of '%s' should be '%s', got '%s'", v, e, r)
+		}
+	}
+}
+
+func TestResolveReference(t *testing.T) {
+	// RFC 3986 section 5.4.
+	base := "http://a/b/c/d;p?q"
+	set := map[string]string{
+		"g:h":           "g:h",
+		"g":             "http://a/b/c/g",
+		"./g":           "http://a/b/c/g",
+		"g/":            "http://a/b/c/g/",
+		"/g":            "http://a/g",
+		"//g":           "http://g",
+		"?y":            "http://a/b/c/d;p?y",
+		"g?y":           "http://a/b/c/g?y",
+		"#s":            "http://a/b/c/d;p?q#s",
+		"g#s":           "http://a/b/c/g#s",
+		"g?y#s":         "http://a/b/c/g?y#s",
+		";x":            "http://a/b/c/;x",
+		"g;x":           "http://a/b/c/g;x",
+		"g;x?y#s":       "http://a/b/c/g;x?y#s",
+		"":              "http://a/b/c/d;p?q",
+		".":             "http://a/b/c/",
+		"./":            "http://a/b/c/",
+		"..":            "http://a/b/",
+		"../":           "http://a/b/",
+		"../g":          "http://a/b/g",
+		"../..":         "http://a/",
+		"../../":        "http://a/",
+		"../../g":       "http://a/g",
+		"../../../g":    "http://a/g",
+		"../../../../g": "http://a/g",
+		"/./g":          "http://a/g",
+		"/../g":         "http://a/g",
+		"g.":            "http://a/b/c/g.",
+		".g":            "http://a/b/c/.g",
+		"g..":           "http://a/b/c/g..",
+		"..g":           "http://a/b/c/..g",
+		"./../g":        "http://a/b/g",
+		"./g/.":         "http://a/b/c/g/",
+		"g/./h":         "http://a/b/c/g/h",
+		"g/../h":        "http://a/b/c/h",
+		"g;x=1/./y":     "http://a/b/c/g;x=1/y",
+		"g;x=1/../y":    "http://a/b/c/y",
+		"g?y/./x":       "http://a/b/c/g?y/./x",
+		"g?y/../x":      "http://a/b/c/g?y/../x",
+		"g#s/./x":       "http://a/b/c/g#s/./x",
+		"g#s/../x":      "http://a/b/c/g#s/../x",
+		"http:g":        "http:g",
+		"ü/ä?ö#ß":       "http://a/b/c/ü/ä?ö#ß",
+	}
+
+	b, err := ParseIri(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, e := range set {
+		ref, err := ParseIriReference(r)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", r, err.Error())
+		}
+		resolved, err := b.ResolveReference(ref)
+		if err != nil {
+			t.Fatalf("resolving '%s' should succeed: %s", r, err.Error())
+		}
+		if resolved.Value != e {
+			t.Fatalf("'%s' should resolve to '%s', got '%s'", r, e, resolved.Value)
+		}
+	}
+
+	noAuthority := map[string]string{
+		"http://x":  "http://x",
+		"..//g":     "a:/g",
+		"/.//g":     "a:/.//g",
+		"c":         "a:c",
+		"#f":        "a:b#f",
+		"http:///x": "http:///x",
+	}
+	b, _ = ParseIri("a:b")
+	for r, e := range noAuthority {
+		ref, _ := ParseIriReference(r)
+		resolved, err := b.ResolveReference(ref)
+		if err != nil {
+			t.Fatalf("resolving '%s' should succeed: %s", r, err.Error())
+		}
+		if resolved.Value != e {
+			t.Fatalf("'%s' should resolve to '%s', got '%s'", r, e, resolved.Value)
+		}
+	}
+
+	relative, _ := ParseIriReference("b/c")
+	ref, _ := ParseIriReference("g")
+	if _, err := relative.ResolveReference(ref); err == nil {
+		t.Fatalf("resolving against a relative reference should fail")
+	}
+}
@@ -0,0 +1,168 @@
+package odin_iri
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// QueryParam is a single pair of an application/x-www-form-urlencoded style query. HasValue is
+// false for a key that appeared without any '='.
+type QueryParam struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// QueryParams is an ordered list of query pairs. Unlike url.Values it keeps the original order,
+// duplicate keys, empty values, keys without '=' and empty pairs between two separators.
+type QueryParams []QueryParam
+
+// QueryParams parses the query of the IRI using '&' as the separator.
+func (i *IRI) QueryParams() (QueryParams, error) {
+	return ParseQuery(i.Query, "&")
+}
+
+// ParseQuery splits query on any of the runes in separators, which defaults to "&" when empty,
+// and decodes '+' and pct-encoded UTF-8 in each key and value into Unicode. Malformed triples and
+// triples that do not decode to UTF-8 fail with the errors of Decode.
+func ParseQuery(query string, separators string) (QueryParams, error) {
+	if separators == "" {
+		separators = "&"
+	}
+	params := make(QueryParams, 0)
+	if query == "" {
+		return params, nil
+	}
+	isSeparator := isSeparatorOf(separators)
+	pairs := make([]string, 0)
+	start := 0
+	for i, r := range query {
+		if isSeparator(r) {
+			pairs = append(pairs, query[start:i])
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	pairs = append(pairs, query[start:])
+
+	for _, pair := range pairs {
+		param := QueryParam{}
+		key, value, hasValue := strings.Cut(pair, "=")
+		var err error
+		if param.Key, err = decodeQueryComponent(key); err != nil {
+			return nil, err
+		}
+		if param.Value, err = decodeQueryComponent(value); err != nil {
+			return nil, err
+		}
+		param.HasValue = hasValue
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// Get returns the value of the first pair with the given key.
+func (q QueryParams) Get(key string) string {
+	for _, param := range q {
+		if param.Key == key {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+// Values returns the values of every pair with the given key in order.
+func (q QueryParams) Values(key string) []string {
+	values := make([]string, 0)
+	for _, param := range q {
+		if param.Key == key {
+			values = append(values, param.Value)
+		}
+	}
+	return values
+}
+
+// Has reports whether a pair with the given key exists.
+func (q QueryParams) Has(key string) bool {
+	for _, param := range q {
+		if param.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends a pair to the end of the list.
+func (q *QueryParams) Add(key, value string) {
+	*q = append(*q, QueryParam{Key: key, Value: value, HasValue: true})
+}
+
+// Set replaces the value of the first pair with the given key and removes any later duplicates,
+// or appends a new pair when the key is not present.
+func (q *QueryParams) Set(key, value string) {
+	for i, param := range *q {
+		if param.Key == key {
+			(*q)[i] = QueryParam{Key: key, Value: value, HasValue: true}
+			rest := (*q)[i+1:]
+			rest.Del(key)
+			*q = append((*q)[:i+1], rest...)
+			return
+		}
+	}
+	q.Add(key, value)
+}
+
+// Del removes every pair with the given key.
+func (q *QueryParams) Del(key string) {
+	kept := (*q)[:0]
+	for _, param := range *q {
+		if param.Key != key {
+			kept = append(kept, param)
+		}
+	}
+	*q = kept
+}
+
+// String encodes the pairs using '&' as the separator.
+func (q QueryParams) String() string {
+	return q.Encode('&')
+}
+
+// Encode joins the pairs with separator into a valid iquery. Spaces become '+', while '&', '=',
+// '+', '#', '%' and the separator itself are pct-encoded along with anything else the iquery
+// grammar does not allow. ucschar and iprivate characters are written as is.
+func (q QueryParams) Encode(separator rune) string {
+	b := strings.Builder{}
+	for i, param := range q {
+		if i > 0 {
+			b.WriteRune(separator)
+		}
+		b.WriteString(encodeQueryComponent(param.Key, separator))
+		if param.HasValue {
+			b.WriteByte('=')
+			b.WriteString(encodeQueryComponent(param.Value, separator))
+		}
+	}
+	return b.String()
+}
+
+func isSeparatorOf(separators string) func(rune) bool {
+	return func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	}
+}
+
+func decodeQueryComponent(value string) (string, error) {
+	return Decode(strings.ReplaceAll(value, "+", " "))
+}
+
+// encodeQueryComponent encodes a key or value as QueryComponent does, also escaping the characters
+// that delimit pairs and writing spaces as '+'.
+func encodeQueryComponent(value string, separator rune) string {
+	encoded := pctEncode(value, func(r rune) bool {
+		if r == '&' || r == '=' || r == '+' || r == separator {
+			return false
+		}
+		return r == ' ' || QueryComponent.allows(r, true)
+	})
+	return strings.ReplaceAll(encoded, " ", "+")
+}
@@ -0,0 +1,102 @@
+package odin_iri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	set := map[string]QueryParams{
+		"a=1&b=2":                         {{"a", "1", true}, {"b", "2", true}},
+		"a=1&a=2&a":                       {{"a", "1", true}, {"a", "2", true}, {"a", "", false}},
+		"a=&=b&&c":                        {{"a", "", true}, {"", "b", true}, {}, {"c", "", false}},
+		"q=caf%C3%A9+au+lait&x=%26%3D%2B": {{"q", "café au lait", true}, {"x", "&=+", true}},
+		"städte=Zürich&emoji=🙂":           {{"städte", "Zürich", true}, {"emoji", "🙂", true}},
+		"a=b=c&":                          {{"a", "b=c", true}, {}},
+		"":                                {},
+	}
+
+	for v, e := range set {
+		params, err := ParseQuery(v, "")
+		if err != nil {
+			t.Fatalf("ParseQuery should succeed with '%s': %s", v, err.Error())
+		}
+		if !reflect.DeepEqual(params, e) {
+			t.Fatalf("ParseQuery of '%s' should be %v, got %v", v, e, params)
+		}
+	}
+
+	params, err := ParseQuery("a=1;b=2&c=3", ";&")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 3 || params.Get("b") != "2" || params.Get("c") != "3" {
+		t.Fatalf("both separators should split pairs, got %v", params)
+	}
+	params, _ = ParseQuery("a=1;b=2", "")
+	if len(params) != 1 || params.Get("a") != "1;b=2" {
+		t.Fatalf("';' should not split with the default separator, got %v", params)
+	}
+
+	failSet := []string{"a=%", "a=%2", "%zz=1", "a=%FF", "%C3=1"}
+	for _, v := range failSet {
+		if _, err = ParseQuery(v, ""); err == nil {
+			t.Fatalf("ParseQuery should have failed with %s", v)
+		}
+	}
+}
+
+func TestQueryParamsEncode(t *testing.T) {
+	params := QueryParams{}
+	params.Add("q", "café au lait")
+	params.Add("ops", "a&b=c+d#e%f;g")
+	params.Add("path", "/x?y:z@w!$'()*,")
+	params.Add("ctl", "\t\"<>\\^`{|}")
+	params.Add("private", "\ue000")
+	params = append(params, QueryParam{Key: "flag"}, QueryParam{})
+	params.Add("", "")
+
+	encoded := params.String()
+	expected := "q=café+au+lait&ops=a%26b%3Dc%2Bd%23e%25f;g&path=/x?y:z@w!$'()*,&ctl=%09%22%3C%3E%5C%5E%60%7B%7C%7D&private=\ue000&flag&&="
+	if encoded != expected {
+		t.Fatalf("encoded query should be '%s', got '%s'", expected, encoded)
+	}
+	iri, err := ParseIri("http://example.org/?" + encoded)
+	if err != nil {
+		t.Fatalf("encoded query should be a valid iquery: %s", err.Error())
+	}
+	decoded, err := iri.QueryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, params) {
+		t.Fatalf("query should round trip as %v, got %v", params, decoded)
+	}
+
+	semicolon := QueryParams{{"a", "1;2", true}, {"b", "x&y", true}}.Encode(';')
+	if semicolon != "a=1%3B2;b=x%26y" {
+		t.Fatalf("separator should be escaped, got '%s'", semicolon)
+	}
+	if decoded, _ = ParseQuery(semicolon, ";"); decoded.Get("a") != "1;2" || decoded.Get("b") != "x&y" {
+		t.Fatalf("semicolon query should round trip, got %v", decoded)
+	}
+}
+
+func TestQueryParamsEdit(t *testing.T) {
+	params, _ := ParseQuery("a=1&b=2&a=3&c", "")
+	if !params.Has("c") || params.Has("d") {
+		t.Fatalf("Has should report present keys only")
+	}
+	if !reflect.DeepEqual(params.Values("a"), []string{"1", "3"}) {
+		t.Fatalf("values of 'a' should be [1 3], got %v", params.Values("a"))
+	}
+	params.Set("a", "x")
+	if params.String() != "a=x&b=2&c" {
+		t.Fatalf("Set should replace the first and drop later duplicates, got '%s'", params.String())
+	}
+	params.Set("d", "y")
+	params.Del("b")
+	if params.String() != "a=x&c&d=y" {
+		t.Fatalf("unexpected query after edits '%s'", params.String())
+	}
+}
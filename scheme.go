@@ -0,0 +1,273 @@
+package odin_iri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Scheme describes the rules a scheme adds on top of the generic IRI grammar.
+type Scheme struct {
+	// Name is the scheme name, matched case-insensitively.
+	Name string
+	// DefaultPort is the port implied when none is given, or empty when the scheme has none.
+	DefaultPort string
+	// RequiresAuthority rejects IRIs of the scheme that have no "//" authority.
+	RequiresAuthority bool
+	// Validate, when set, checks an already parsed IRI of the scheme.
+	Validate func(iri *IRI) error
+	// Normalize, when set, returns the scheme-based normal form of an IRI (RFC 3987 section
+	// 5.3.3). Schemes without one only have their name lowercased.
+	Normalize func(iri *IRI) (*IRI, error)
+}
+
+// SchemeError reports an IRI that matches the generic grammar but breaks the rules of its scheme.
+type SchemeError struct {
+	Scheme string
+	Err    error
+}
+
+func (s SchemeError) Error() string {
+	return fmt.Sprintf("scheme: %s, message: %s", s.Scheme, s.Err.Error())
+}
+
+func (s SchemeError) Unwrap() error {
+	return s.Err
+}
+
+// SchemeRegistry maps scheme names to their Scheme. It is safe for concurrent use.
+type SchemeRegistry struct {
+	mu      sync.RWMutex
+	schemes map[string]Scheme
+}
+
+// NewSchemeRegistry returns an empty registry.
+func NewSchemeRegistry() *SchemeRegistry {
+	return &SchemeRegistry{schemes: make(map[string]Scheme)}
+}
+
+// DefaultSchemeRegistry holds the schemes shipped with the package: http, https, ftp, ws, wss,
+// file, mailto, urn, data and tel.
+var DefaultSchemeRegistry = newDefaultSchemeRegistry()
+
+// WithSchemeValidation makes ParseIri check IRIs against the Scheme registered for their scheme
+// name. Schemes missing from the registry are accepted as is. A nil registry uses
+// DefaultSchemeRegistry.
+func WithSchemeValidation(registry *SchemeRegistry) Option {
+	if registry == nil {
+		registry = DefaultSchemeRegistry
+	}
+	return func(o *options) {
+		o.schemes = registry
+	}
+}
+
+// Register adds a scheme, replacing any previous registration of the same name.
+func (r *SchemeRegistry) Register(scheme Scheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemes[strings.ToLower(scheme.Name)] = scheme
+}
+
+// Lookup returns the scheme registered under name.
+func (r *SchemeRegistry) Lookup(name string) (Scheme, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scheme, ok := r.schemes[strings.ToLower(name)]
+	return scheme, ok
+}
+
+// Validate applies the rules of the IRI's scheme, returning a SchemeError when one is broken.
+func (r *SchemeRegistry) Validate(iri *IRI) error {
+	scheme, ok := r.Lookup(iri.Scheme)
+	if !ok {
+		return nil
+	}
+	if scheme.RequiresAuthority && !iri.Spans().Host.Present() {
+		return SchemeError{Scheme: scheme.Name, Err: errors.New("an authority is required")}
+	}
+	if scheme.Validate != nil {
+		if err := scheme.Validate(iri); err != nil {
+			return SchemeError{Scheme: scheme.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// Normalize returns the scheme-based normal form of the IRI using its registered normalizer, or
+// the IRI with a lowercased scheme name when the scheme has none.
+func (r *SchemeRegistry) Normalize(iri *IRI) (*IRI, error) {
+	scheme, ok := r.Lookup(iri.Scheme)
+	if ok && scheme.Normalize != nil {
+		return scheme.Normalize(iri)
+	}
+	parts := iri.parts()
+	parts.scheme = strings.ToLower(parts.scheme)
+	return ParseIriReference(parts.String(), WithZoneID())
+}
+
+// DefaultPort returns the default port of the scheme, or an empty string when it is unknown.
+func (r *SchemeRegistry) DefaultPort(name string) string {
+	scheme, _ := r.Lookup(name)
+	return scheme.DefaultPort
+}
+
+func newDefaultSchemeRegistry() *SchemeRegistry {
+	r := NewSchemeRegistry()
+	for _, name := range []string{"http", "https", "ws", "wss", "ftp"} {
+		scheme := Scheme{
+			Name:              name,
+			RequiresAuthority: true,
+			Validate:          validateNetworkHost,
+		}
+		switch name {
+		case "http", "ws":
+			scheme.DefaultPort = "80"
+		case "https", "wss":
+			scheme.DefaultPort = "443"
+		case "ftp":
+			scheme.DefaultPort = "21"
+		}
+		if name == "ws" || name == "wss" {
+			scheme.Validate = validateWebSocket
+		}
+		scheme.Normalize = normalizeNetwork(scheme.DefaultPort)
+		r.Register(scheme)
+	}
+	r.Register(Scheme{Name: "file", Validate: validateFile, Normalize: normalizeNetwork("")})
+	r.Register(Scheme{Name: "mailto", Validate: validateMailtoSyntax})
+	r.Register(Scheme{Name: "urn", Validate: validateUrnSyntax})
+	r.Register(Scheme{Name: "data", Validate: validateDataSyntax})
+	r.Register(Scheme{Name: "tel", Validate: validateTelSyntax})
+	return r
+}
+
+func validateNetworkHost(iri *IRI) error {
+	if iri.Host() == "" {
+		return errors.New("the host must not be empty")
+	}
+	return nil
+}
+
+func validateWebSocket(iri *IRI) error {
+	if iri.Spans().Fragment.Present() {
+		return errors.New("fragments are not allowed")
+	}
+	return validateNetworkHost(iri)
+}
+
+func validateFile(iri *IRI) error {
+	if iri.Spans().UserInfo.Present() || iri.Spans().Port.Present() {
+		return errors.New("file authorities only hold a host")
+	}
+	if iri.Path != "" && iri.Path[0] != '/' {
+		return errors.New("the path must be absolute")
+	}
+	return nil
+}
+
+func validateMailtoSyntax(iri *IRI) error {
+	_, err := ParseMailto(iri)
+	return err
+}
+
+func validateUrnSyntax(iri *IRI) error {
+	_, err := ParseURN(iri)
+	return err
+}
+
+func validateDataSyntax(iri *IRI) error {
+	_, err := ParseDataURI(iri)
+	return err
+}
+
+func validateTelSyntax(iri *IRI) error {
+	_, err := ParseTelURI(iri)
+	return err
+}
+
+// normalizeNetwork returns a normalizer applying the syntax and scheme-based normalizations of
+// RFC 3986 section 6.2.2 and 6.2.3: lowercase scheme and host, uppercase pct-encoding hex digits,
+// decode pct-encoded unreserved characters, remove dot-segments, drop an empty or default port
+// and use "/" for an empty path when there is an authority.
+func normalizeNetwork(defaultPort string) func(iri *IRI) (*IRI, error) {
+	return func(iri *IRI) (*IRI, error) {
+		parts := iri.parts()
+		parts.scheme = strings.ToLower(parts.scheme)
+		if parts.hasAuthority {
+			authority := ""
+			if iri.Spans().UserInfo.Present() {
+				authority = normalizePctEncoding(decodeUnreserved(iri.UserInfo())) + "@"
+			}
+			if iri.ipv6 != nil {
+				authority += iri.CanonicalHost()
+			} else {
+				authority += normalizePctEncoding(strings.Map(lowerASCII, decodeUnreserved(iri.Host())))
+			}
+			if port := strings.TrimLeft(iri.Port(), "0"); iri.Port() != "" && port != defaultPort {
+				if port == "" {
+					port = "0"
+				}
+				authority += ":" + port
+			}
+			parts.authority = authority
+			if parts.path == "" {
+				parts.path = "/"
+			}
+		}
+		parts.path = normalizePctEncoding(decodeUnreserved(parts.path))
+		if strings.HasPrefix(parts.path, "/") {
+			parts.path = RemoveDotSegments(parts.path)
+		}
+		parts.query = normalizePctEncoding(decodeUnreserved(parts.query))
+		parts.fragment = normalizePctEncoding(decodeUnreserved(parts.fragment))
+		return ParseIriReference(parts.String(), WithZoneID())
+	}
+}
+
+// decodeUnreserved decodes the pct-encoded triples that stand for unreserved characters, RFC
+// 3986 section 6.2.2.2.
+func decodeUnreserved(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	b := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) && isHexDigit(rune(value[i+1])) && isHexDigit(rune(value[i+2])) {
+			if c := pctDecode(value[i : i+3])[0]; isUnreserved(rune(c)) {
+				b.WriteByte(c)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// normalizePctEncoding uppercases the hexadecimal digits of every pct-encoded triple.
+func normalizePctEncoding(value string) string {
+	b := []byte(value)
+	for i := 0; i+2 < len(b); i++ {
+		if b[i] == '%' {
+			b[i+1], b[i+2] = upperASCII(b[i+1]), upperASCII(b[i+2])
+			i += 2
+		}
+	}
+	return string(b)
+}
+
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func lowerASCII(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r - 'A' + 'a'
+	}
+	return r
+}
@@ -0,0 +1,128 @@
+package odin_iri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchemeValidation(t *testing.T) {
+	failSet := []string{
+		"http:/no/authority",
+		"http:///empty/host",
+		"HTTPS:relative",
+		"ftp://",
+		"ws://example.org/chat#fragment",
+		"file://user@host/etc/hosts",
+		"file://host:21/etc/hosts",
+		"mailto://example.org",
+		"mailto:not-an-address",
+		"mailto:a@example.org?cc=nobody",
+		"mailto:a@example.org?subject",
+		"urn:isbn",
+		"urn:-bad:nss",
+		"urn://x/y",
+		"data:text/plain",
+		"data:text,abc",
+		"data:text/plain;charset,abc",
+		"tel:",
+	}
+	goodSet := []string{
+		"http://example.org",
+		"HTTPS://example.org:8443/a?b#c",
+		"ftp://ftp.is.co.za/rfc/rfc1808.txt",
+		"wss://example.org/chat",
+		"file:///etc/hosts",
+		"file://localhost/etc/hosts",
+		"file:/etc/hosts",
+		"mailto:John.Doe@example.com",
+		"MAILTO:a@example.org,b@example.org?subject=Hi&body=x+y",
+		"mailto:?to=a@example.org",
+		"urn:isbn:0451450523",
+		"data:,Hello%2C%20World!",
+		"DATA:text/plain;charset=utf-8;base64,SGk=",
+		"tel:+1-816-555-1212",
+		"x-unknown:anything/goes",
+	}
+
+	for _, v := range failSet {
+		if _, err := ParseIri(v); err != nil {
+			t.Fatalf("ParseIri without validation should succeed with '%s': %s", v, err.Error())
+		}
+		_, err := ParseIri(v, WithSchemeValidation(nil))
+		var schemeErr SchemeError
+		if !errors.As(err, &schemeErr) {
+			t.Fatalf("ParseIri should have failed with a SchemeError for %s, got %v", v, err)
+		}
+	}
+
+	for _, v := range goodSet {
+		if _, err := ParseIri(v, WithSchemeValidation(nil)); err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+	}
+}
+
+func TestCustomScheme(t *testing.T) {
+	registry := NewSchemeRegistry()
+	registry.Register(Scheme{
+		Name:              "Acme",
+		DefaultPort:       "7000",
+		RequiresAuthority: true,
+		Validate: func(iri *IRI) error {
+			if iri.Query != "" {
+				return errors.New("queries are not supported")
+			}
+			return nil
+		},
+	})
+	if _, err := ParseIri("acme://host/path", WithSchemeValidation(registry)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseIri("ACME://host/path?q", WithSchemeValidation(registry)); err == nil {
+		t.Fatalf("the custom validator should reject queries")
+	}
+	if _, err := ParseIri("acme:path", WithSchemeValidation(registry)); err == nil {
+		t.Fatalf("the custom scheme should require an authority")
+	}
+	// The custom registry does not know http, so it is not checked.
+	if _, err := ParseIri("http:path", WithSchemeValidation(registry)); err != nil {
+		t.Fatal(err)
+	}
+	if registry.DefaultPort("ACME") != "7000" || registry.DefaultPort("http") != "" {
+		t.Fatalf("unexpected default ports")
+	}
+	if DefaultSchemeRegistry.DefaultPort("wss") != "443" {
+		t.Fatalf("wss should default to port 443")
+	}
+}
+
+func TestSchemeNormalize(t *testing.T) {
+	set := map[string]string{
+		"HTTP://User@Example.ORG:80":                "http://User@example.org/",
+		"http://example.org:0080/%7e%c3%a4?%2f#%2f": "http://example.org/~%C3%A4?%2F#%2F",
+		"http://example.org/a/./b/../c/%7euser":     "http://example.org/a/c/~user",
+		"http://%45x.org/%2E%2E/%61?%41#%5F":        "http://ex.org/a?A#_",
+		"http://u%2Dser@x.org/a/%2e/b":              "http://u-ser@x.org/a/b",
+		"https://example.org:80/":                   "https://example.org:80/",
+		"https://example.org:/":                     "https://example.org/",
+		"HTTP://[2001:DB8:0:0:0:0:0:1]:8080":        "http://[2001:db8::1]:8080/",
+		"http://EX%c3%a4MPLE.org/":                  "http://ex%C3%A4mple.org/",
+		"FILE://LOCALHOST/etc":                      "file://localhost/etc",
+		"URN:ISBN:0451450523":                       "urn:ISBN:0451450523",
+		"X-Custom://Host/":                          "x-custom://Host/",
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		normalized, err := DefaultSchemeRegistry.Normalize(iri)
+		if err != nil {
+			t.Fatalf("Normalize should succeed with '%s': %s", v, err.Error())
+		}
+		if normalized.Value != e {
+			t.Fatalf("normal form of '%s' should be '%s', got '%s'", v, e, normalized.Value)
+		}
+	}
+}
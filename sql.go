@@ -0,0 +1,97 @@
+package odin_iri
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// StorageForm selects the text written to and expected from a database column.
+type StorageForm int
+
+const (
+	// StoreDefault defers to DefaultStorageForm.
+	StoreDefault StorageForm = iota
+	// StoreIRI stores the IRI exactly as parsed.
+	StoreIRI
+	// StoreURI stores the URI mapping of the IRI (RFC 3987 section 3.1) and converts it back into
+	// an IRI when scanning, for columns or drivers that only accept ASCII.
+	StoreURI
+)
+
+// DefaultStorageForm is the form used by IRI.Scan and by any NullIRI whose Form is StoreDefault.
+var DefaultStorageForm = StoreIRI
+
+// Scan implements sql.Scanner. The column must hold a string or byte slice containing a valid IRI
+// in the DefaultStorageForm; a NULL column is an error, use NullIRI for nullable columns.
+//
+// IRI cannot implement driver.Valuer as its Value field already occupies the method name, so
+// values are written through NullIRI instead.
+func (i *IRI) Scan(src any) error {
+	return i.scan(src, DefaultStorageForm)
+}
+
+func (i *IRI) scan(src any, form StorageForm) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case nil:
+		return errors.New("cannot scan NULL into IRI")
+	default:
+		return fmt.Errorf("cannot scan %T into IRI", src)
+	}
+	if resolveStorageForm(form) == StoreURI {
+		text = uriToIri(text)
+	}
+	parsed, err := ParseIri(text)
+	if err != nil {
+		return err
+	}
+	*i = *parsed
+	return nil
+}
+
+// NullIRI represents an IRI that may be NULL, in the manner of sql.NullString. It implements both
+// sql.Scanner and driver.Valuer.
+type NullIRI struct {
+	IRI   IRI
+	Valid bool
+	// Form overrides DefaultStorageForm for this value when it is not StoreDefault.
+	Form StorageForm
+}
+
+// Scan implements sql.Scanner.
+func (n *NullIRI) Scan(src any) error {
+	if src == nil {
+		n.IRI, n.Valid = IRI{}, false
+		return nil
+	}
+	if err := n.IRI.scan(src, n.Form); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer, returning the IRI in the configured storage form or nil when
+// the value is not valid.
+func (n NullIRI) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	if resolveStorageForm(n.Form) == StoreURI {
+		return n.IRI.URI(), nil
+	}
+	return n.IRI.Value, nil
+}
+
+func resolveStorageForm(form StorageForm) StorageForm {
+	if form == StoreDefault {
+		return DefaultStorageForm
+	}
+	return form
+}
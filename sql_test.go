@@ -0,0 +1,151 @@
+package odin_iri
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeDriver is a single table, single column, in-memory database. "INSERT" appends its only
+// argument and "SELECT" returns every stored value in insertion order.
+type fakeDriver struct {
+	rows []driver.Value
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	rows  []driver.Value
+	index int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query != "INSERT" || len(args) != 1 {
+		return nil, errors.New("unsupported")
+	}
+	s.d.rows = append(s.d.rows, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.query != "SELECT" {
+		return nil, errors.New("unsupported")
+	}
+	return &fakeRows{rows: s.d.rows}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"iri"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.rows) {
+		return io.EOF
+	}
+	dest[0] = r.rows[r.index]
+	r.index++
+	return nil
+}
+
+var fakeDB = &fakeDriver{}
+
+func init() {
+	sql.Register("odin-iri-fake", fakeDB)
+}
+
+func TestSQL(t *testing.T) {
+	db, err := sql.Open("odin-iri-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fakeDB.rows = nil
+
+	iri, _ := ParseIri("http://exämple.org/ü?q=ä")
+	inserts := []NullIRI{
+		{IRI: *iri, Valid: true},
+		{IRI: *iri, Valid: true, Form: StoreURI},
+		{},
+	}
+	for _, v := range inserts {
+		if _, err = db.Exec("INSERT", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.Exec("INSERT", "not an iri"); err != nil {
+		t.Fatal(err)
+	}
+	if fakeDB.rows[0] != iri.Value || fakeDB.rows[1] != "http://ex%C3%A4mple.org/%C3%BC?q=%C3%A4" || fakeDB.rows[2] != nil {
+		t.Fatalf("unexpected stored values %v", fakeDB.rows)
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	scans := []NullIRI{{}, {Form: StoreURI}, {}, {}}
+	for i := range scans {
+		if !rows.Next() {
+			t.Fatalf("expected row %d", i)
+		}
+		err = rows.Scan(&scans[i])
+		if i == 3 {
+			var iriErr IriError
+			if !errors.As(err, &iriErr) {
+				t.Fatalf("scanning an invalid IRI should fail with an IriError, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !scans[0].Valid || scans[0].IRI.Value != iri.Value {
+		t.Fatalf("stored IRI should scan back, got %+v", scans[0])
+	}
+	if !scans[1].Valid || scans[1].IRI.Value != iri.Value {
+		t.Fatalf("stored URI should scan back into the IRI, got %+v", scans[1])
+	}
+	if scans[2].Valid {
+		t.Fatalf("NULL should scan as invalid, got %+v", scans[2])
+	}
+}
+
+func TestScan(t *testing.T) {
+	var iri IRI
+	if err := iri.Scan([]byte("urn:isbn:0451450523")); err != nil || iri.Path != "isbn:0451450523" {
+		t.Fatalf("Scan should parse bytes, got %+v (%v)", iri, err)
+	}
+	if err := iri.Scan(nil); err == nil {
+		t.Fatalf("Scan should fail for NULL")
+	}
+	if err := iri.Scan(42); err == nil {
+		t.Fatalf("Scan should fail for a number")
+	}
+
+	DefaultStorageForm = StoreURI
+	defer func() { DefaultStorageForm = StoreIRI }()
+	if err := iri.Scan("http://example.org/%E2%82%AC"); err != nil || iri.Path != "/€" {
+		t.Fatalf("Scan should convert the URI form, got %+v (%v)", iri, err)
+	}
+	value, _ := NullIRI{IRI: iri, Valid: true}.Value()
+	if value != "http://example.org/%E2%82%AC" {
+		t.Fatalf("Value should use the default storage form, got %v", value)
+	}
+}
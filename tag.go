@@ -0,0 +1,122 @@
+package odin_iri
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TagURI is a "tag:" IRI (RFC 4151). The specific part and fragment are kept in the pct-encoded
+// form they have in the IRI.
+type TagURI struct {
+	// AuthorityName is the DNS name or email address of the tagging entity.
+	AuthorityName string
+	// Date is the date of the tagging entity, as "YYYY", "YYYY-MM" or "YYYY-MM-DD".
+	Date string
+	// Specific is the specific part that follows the ':' after the date.
+	Specific string
+	// Fragment holds the fragment, when HasFragment is set.
+	Fragment    string
+	HasFragment bool
+}
+
+var (
+	dnsNameSyntax = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+	emailLocal    = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	tagDateSyntax = regexp.MustCompile(`^[0-9]{4}(-[0-9]{2}(-[0-9]{2})?)?$`)
+)
+
+// ParseTagURI extracts the tagging entity, specific part and fragment of a "tag:" IRI. A '?' in
+// the specific part starts the query of the IRI, so the query is joined back onto it.
+func ParseTagURI(iri *IRI) (*TagURI, error) {
+	if !strings.EqualFold(iri.Scheme, "tag") {
+		return nil, errors.New("not a tag uri")
+	}
+	if iri.Spans().Host.Present() {
+		return nil, errors.New("a tag uri cannot have an authority")
+	}
+	entity, specific, ok := strings.Cut(iri.Path, ":")
+	if !ok {
+		return nil, errors.New("tag uri is missing ':' after the tagging entity")
+	}
+	authorityName, date, ok := strings.Cut(entity, ",")
+	if !ok {
+		return nil, errors.New("tag uri is missing ',' before the date")
+	}
+	if iri.Spans().Query.Present() {
+		specific += "?" + iri.Query
+	}
+	tag := &TagURI{AuthorityName: authorityName, Date: date, Specific: specific}
+	if iri.Spans().Fragment.Present() {
+		tag.Fragment, tag.HasFragment = iri.Fragment, true
+	}
+	if err := tag.validateEntity(); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// NewTagURI mints a tag. The specific part is pct-encoded where needed, leaving non-ASCII
+// characters as they are.
+func NewTagURI(authorityName, date, specific string) (*TagURI, error) {
+	tag := &TagURI{AuthorityName: authorityName, Date: date, Specific: Encode(FragmentComponent, specific)}
+	if err := tag.validateEntity(); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// validateEntity checks the authority name and the date of the tagging entity.
+func (t *TagURI) validateEntity() error {
+	name := t.AuthorityName
+	if local, domain, ok := strings.Cut(name, "@"); ok {
+		if !emailLocal.MatchString(local) {
+			return errors.New("invalid tag email address")
+		}
+		name = domain
+	}
+	if !dnsNameSyntax.MatchString(name) {
+		return errors.New("invalid tag authority name")
+	}
+	_, err := t.Time()
+	return err
+}
+
+// Time returns the start of the day, month or year given by Date, in UTC.
+func (t *TagURI) Time() (time.Time, error) {
+	if !tagDateSyntax.MatchString(t.Date) {
+		return time.Time{}, errors.New("tag date must be YYYY, YYYY-MM or YYYY-MM-DD")
+	}
+	layout := "2006-01-02"[:len(t.Date)]
+	date, err := time.Parse(layout, t.Date)
+	if err != nil {
+		return time.Time{}, errors.New("invalid tag date")
+	}
+	return date, nil
+}
+
+// Tag returns the tag without its fragment.
+func (t *TagURI) Tag() string {
+	return "tag:" + t.AuthorityName + "," + t.Date + ":" + t.Specific
+}
+
+// String returns the tag IRI including its fragment.
+func (t *TagURI) String() string {
+	if t.HasFragment {
+		return t.Tag() + "#" + t.Fragment
+	}
+	return t.Tag()
+}
+
+// IRI parses the tag back into an IRI.
+func (t *TagURI) IRI() (*IRI, error) {
+	return ParseIri(t.String())
+}
+
+// Equal reports whether two tags are the same following RFC 4151 section 2.4: tags are compared
+// character by character, without case folding or pct-encoding normalization. The fragment
+// identifies a part of the tagged resource and is not compared.
+func (t *TagURI) Equal(other *TagURI) bool {
+	return t.Tag() == other.Tag()
+}
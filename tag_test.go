@@ -0,0 +1,142 @@
+package odin_iri
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTagURI(t *testing.T) {
+	type expected struct {
+		authorityName string
+		date          string
+		specific      string
+		fragment      string
+		hasFragment   bool
+	}
+	set := map[string]expected{
+		"tag:example.com,2024:item/42":                 {"example.com", "2024", "item/42", "", false},
+		"tag:timothy@hpl.hp.com,2001:web/externalHome": {"timothy@hpl.hp.com", "2001", "web/externalHome", "", false},
+		"tag:sandro@w3.org,2004-05:Sandro":             {"sandro@w3.org", "2004-05", "Sandro", "", false},
+		"tag:my-ids.com,2001-09-15:TimKindberg:presentations:UBath2004-05-19": {
+			"my-ids.com", "2001-09-15", "TimKindberg:presentations:UBath2004-05-19", "", false},
+		"tag:blogger.com,1999:blog-555?a=b#section": {"blogger.com", "1999", "blog-555?a=b", "section", true},
+		"tag:example.com,2024-02-29:":               {"example.com", "2024-02-29", "", "", false},
+		"tag:example.com,2024:caf%C3%A9/日本":         {"example.com", "2024", "caf%C3%A9/日本", "", false},
+		"tag:first.last_1@example.com,2024:x#":      {"first.last_1@example.com", "2024", "x", "", true},
+	}
+
+	for v, e := range set {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		tag, err := ParseTagURI(iri)
+		if err != nil {
+			t.Fatalf("ParseTagURI should succeed with '%s': %s", v, err.Error())
+		}
+		if tag.AuthorityName != e.authorityName || tag.Date != e.date || tag.Specific != e.specific ||
+			tag.Fragment != e.fragment || tag.HasFragment != e.hasFragment {
+			t.Fatalf("unexpected tag for '%s': %+v", v, tag)
+		}
+		if tag.String() != v {
+			t.Fatalf("tag '%s' should print as itself, got '%s'", v, tag.String())
+		}
+	}
+
+	failSet := []string{
+		"urn:example.com,2024:x",
+		"tag://example.com/x",
+		"tag:example.com:x",
+		"tag:example.com,2024",
+		"tag:,2024:x",
+		"tag:-example.com,2024:x",
+		"tag:example..com,2024:x",
+		"tag:exa_mple.com,2024:x",
+		"tag:@example.com,2024:x",
+		"tag:a+b@example.com,2024:x",
+		"tag:example.com,24:x",
+		"tag:example.com,2024-1:x",
+		"tag:example.com,2024-13:x",
+		"tag:example.com,2023-02-29:x",
+		"tag:example.com,2024-01-01T00:x",
+		"tag:ex%41mple.com,2024:x",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := ParseTagURI(iri); err == nil {
+			t.Fatalf("ParseTagURI should fail with '%s'", v)
+		}
+	}
+}
+
+func TestNewTagURI(t *testing.T) {
+	tag, err := NewTagURI("example.com", "2024-03", "item 42/ü?x#y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "tag:example.com,2024-03:item%2042/ü?x%23y"; tag.String() != e {
+		t.Fatalf("tag should be '%s', got '%s'", e, tag.String())
+	}
+	iri, err := tag.IRI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTagURI(iri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(tag) {
+		t.Fatalf("'%s' should round trip", tag.String())
+	}
+	if date, _ := tag.Time(); !date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date %v", date)
+	}
+
+	if _, err := NewTagURI("example.com", "2024-02-30", "x"); err == nil {
+		t.Fatalf("NewTagURI should reject an invalid date")
+	}
+	if _, err := NewTagURI("not an authority", "2024", "x"); err == nil {
+		t.Fatalf("NewTagURI should reject an invalid authority name")
+	}
+}
+
+func TestTagURIEqual(t *testing.T) {
+	equalSet := [][2]string{
+		{"tag:example.com,2024:x", "tag:example.com,2024:x"},
+		{"tag:example.com,2024:x#a", "tag:example.com,2024:x#b"},
+	}
+	differentSet := [][2]string{
+		{"tag:example.com,2024:x", "tag:Example.com,2024:x"},
+		{"tag:example.com,2024:x", "tag:example.com,2024-01:x"},
+		{"tag:example.com,2024:x", "tag:example.com,2024:X"},
+		{"tag:example.com,2024:%7e", "tag:example.com,2024:%7E"},
+		{"tag:example.com,2024:%7E", "tag:example.com,2024:~"},
+	}
+
+	parse := func(v string) *TagURI {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		tag, err := ParseTagURI(iri)
+		if err != nil {
+			t.Fatalf("ParseTagURI should succeed with '%s': %s", v, err.Error())
+		}
+		return tag
+	}
+
+	for _, pair := range equalSet {
+		if !parse(pair[0]).Equal(parse(pair[1])) {
+			t.Fatalf("'%s' and '%s' should be equal", pair[0], pair[1])
+		}
+	}
+	for _, pair := range differentSet {
+		if parse(pair[0]).Equal(parse(pair[1])) {
+			t.Fatalf("'%s' and '%s' should differ", pair[0], pair[1])
+		}
+	}
+}
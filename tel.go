@@ -0,0 +1,243 @@
+package odin_iri
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TelURI is a "tel:" IRI (RFC 3966). Values are decoded, so a '#' of a local number written as
+// "%23" in the IRI is held as '#'.
+type TelURI struct {
+	// Number is the telephone number with its visual separators, starting with '+' for a global
+	// number.
+	Number string
+	// Extension is the "ext" parameter, empty when absent.
+	Extension string
+	// ISDNSubaddress is the "isub" parameter, empty when absent.
+	ISDNSubaddress string
+	// PhoneContext is the "phone-context" parameter, a domain name or global number digits. It is
+	// required for local numbers and absent for global ones.
+	PhoneContext string
+	// Params holds the other parameters in order with decoded values.
+	Params []TelParam
+}
+
+// TelParam is a parameter of a "tel:" IRI other than "ext", "isub" and "phone-context".
+type TelParam struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+var (
+	telGlobalNumber = regexp.MustCompile(`^\+[0-9.()-]*[0-9][0-9.()-]*$`)
+	telLocalNumber  = regexp.MustCompile(`^[0-9A-Fa-f*#.()-]*[0-9A-Fa-f*#][0-9A-Fa-f*#.()-]*$`)
+	telPhoneDigits  = regexp.MustCompile(`^[0-9.()-]*[0-9][0-9.()-]*$`)
+	telDomainName   = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?\.)*[A-Za-z]([A-Za-z0-9-]*[A-Za-z0-9])?\.?$`)
+	telParamName    = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// ParseTelURI extracts the number and parameters of a "tel:" IRI. Parameter names are matched
+// case-insensitively and parameters may appear in any order, but "ext", "isub" and
+// "phone-context" may each only appear once.
+func ParseTelURI(iri *IRI) (*TelURI, error) {
+	if !strings.EqualFold(iri.Scheme, "tel") {
+		return nil, errors.New("not a tel uri")
+	}
+	if iri.Spans().Host.Present() || iri.Spans().Query.Present() || iri.Spans().Fragment.Present() {
+		return nil, errors.New("a tel uri only has a path")
+	}
+	parameters := strings.Split(iri.Path, ";")
+	number, err := Decode(parameters[0])
+	if err != nil {
+		return nil, err
+	}
+	tel := &TelURI{Number: number}
+	seen := make(map[string]bool)
+	for _, parameter := range parameters[1:] {
+		name, rawValue, hasValue := strings.Cut(parameter, "=")
+		lower := strings.ToLower(name)
+		// isub holds uric characters, a wider set than the paramchar of the other parameters.
+		if !telParamName.MatchString(name) || hasValue && lower != "isub" && !isTelParamValue(rawValue) {
+			return nil, errors.New("invalid tel parameter")
+		}
+		value, err := Decode(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		switch lower {
+		case "ext", "isub", "phone-context":
+			if seen[lower] || !hasValue {
+				return nil, errors.New("tel parameter " + lower + " must appear once with a value")
+			}
+			seen[lower] = true
+		}
+		switch lower {
+		case "ext":
+			if !telPhoneDigits.MatchString(value) {
+				return nil, errors.New("invalid tel extension")
+			}
+			tel.Extension = value
+		case "isub":
+			tel.ISDNSubaddress = value
+		case "phone-context":
+			if !telDomainName.MatchString(value) && !telGlobalNumber.MatchString(value) {
+				return nil, errors.New("invalid tel phone-context")
+			}
+			tel.PhoneContext = value
+		default:
+			tel.Params = append(tel.Params, TelParam{Name: name, Value: value, HasValue: hasValue})
+		}
+	}
+
+	if tel.IsGlobal() {
+		if !telGlobalNumber.MatchString(number) {
+			return nil, errors.New("invalid global tel number")
+		}
+		if tel.PhoneContext != "" {
+			return nil, errors.New("a global tel number cannot have a phone-context")
+		}
+	} else {
+		if !telLocalNumber.MatchString(number) {
+			return nil, errors.New("invalid local tel number")
+		}
+		if tel.PhoneContext == "" {
+			return nil, errors.New("a local tel number requires a phone-context")
+		}
+	}
+	return tel, nil
+}
+
+// IsGlobal reports whether the number is a global number, written in E.164 form with a leading
+// '+'.
+func (t *TelURI) IsGlobal() bool {
+	return strings.HasPrefix(t.Number, "+")
+}
+
+// Digits returns the number without its visual separators.
+func (t *TelURI) Digits() string {
+	return stripVisualSeparators(t.Number)
+}
+
+// Param returns the decoded value of the first parameter named name, compared case-insensitively.
+// The "ext", "isub" and "phone-context" parameters are found as well.
+func (t *TelURI) Param(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "ext":
+		return t.Extension, t.Extension != ""
+	case "isub":
+		return t.ISDNSubaddress, t.ISDNSubaddress != ""
+	case "phone-context":
+		return t.PhoneContext, t.PhoneContext != ""
+	}
+	for _, param := range t.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns the "tel:" IRI with the parameters in the order RFC 3966 section 5.4 recommends:
+// "ext" or "isub" first, then "phone-context", then the other parameters in their own order.
+func (t *TelURI) String() string {
+	b := strings.Builder{}
+	b.WriteString("tel:")
+	b.WriteString(strings.ReplaceAll(t.Number, "#", "%23"))
+	if t.Extension != "" {
+		b.WriteString(";ext=" + t.Extension)
+	}
+	if t.ISDNSubaddress != "" {
+		b.WriteString(";isub=" + encodeTelParamValue(t.ISDNSubaddress))
+	}
+	if t.PhoneContext != "" {
+		b.WriteString(";phone-context=" + t.PhoneContext)
+	}
+	for _, param := range t.Params {
+		b.WriteString(";" + param.Name)
+		if param.HasValue {
+			b.WriteString("=" + encodeTelParamValue(param.Value))
+		}
+	}
+	return b.String()
+}
+
+// IRI builds the "tel:" IRI, failing when the number or a parameter is invalid.
+func (t *TelURI) IRI() (*IRI, error) {
+	iri, err := ParseIri(t.String())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseTelURI(iri); err != nil {
+		return nil, err
+	}
+	return iri, nil
+}
+
+// Equivalent reports whether two tel IRIs are equal under RFC 3966 section 4: both must be global
+// or both local, the numbers are compared without visual separators, the parameters are compared
+// regardless of their order, and the comparison is case-insensitive. Visual separators are also
+// ignored in the extension and in a global number phone-context.
+func (t *TelURI) Equivalent(other *TelURI) bool {
+	return t.equivalenceKey() == other.equivalenceKey()
+}
+
+func (t *TelURI) equivalenceKey() string {
+	params := make([]string, 0, len(t.Params)+3)
+	if t.Extension != "" {
+		params = append(params, "ext="+stripVisualSeparators(t.Extension))
+	}
+	if t.ISDNSubaddress != "" {
+		params = append(params, "isub="+encodeTelParamValue(t.ISDNSubaddress))
+	}
+	if t.PhoneContext != "" {
+		context := t.PhoneContext
+		if strings.HasPrefix(context, "+") {
+			context = stripVisualSeparators(context)
+		}
+		params = append(params, "phone-context="+strings.TrimSuffix(context, "."))
+	}
+	for _, param := range t.Params {
+		if param.HasValue {
+			params = append(params, param.Name+"="+encodeTelParamValue(param.Value))
+		} else {
+			params = append(params, param.Name)
+		}
+	}
+	for p := range params {
+		params[p] = strings.ToLower(params[p])
+	}
+	sort.Strings(params)
+	return strings.ToLower(t.Digits()) + ";" + strings.Join(params, ";")
+}
+
+func stripVisualSeparators(value string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("-.()", r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// isTelParamValue checks 1*paramchar, where paramchar is unreserved, pct-encoded or one of
+// "[]/:&+$", extended with ucschar for IRIs.
+func isTelParamValue(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if !isUnreserved(r) && !isUcsChar(r) && !strings.ContainsRune("[]/:&+$%", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeTelParamValue(value string) string {
+	return pctEncode(value, func(r rune) bool {
+		return isUnreserved(r) || isUcsChar(r) || strings.ContainsRune("/:&+$", r)
+	})
+}
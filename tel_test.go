@@ -0,0 +1,137 @@
+package odin_iri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTelURI(t *testing.T) {
+	set := map[string]TelURI{
+		"tel:+1-201-555-0123":                        {Number: "+1-201-555-0123"},
+		"tel:+1(201)555.0123;ext=1234":               {Number: "+1(201)555.0123", Extension: "1234"},
+		"tel:7042;phone-context=example.com":         {Number: "7042", PhoneContext: "example.com"},
+		"tel:863-1234;phone-context=+1-914-555":      {Number: "863-1234", PhoneContext: "+1-914-555"},
+		"tel:*21%23;phone-context=example.com.":      {Number: "*21#", PhoneContext: "example.com."},
+		"tel:+358-555-1234567;postd=pp22":            {Number: "+358-555-1234567", Params: []TelParam{{"postd", "pp22", true}}},
+		"tel:+1-555-0100;ISUB=1411,%3Bx;EXT=5":       {Number: "+1-555-0100", Extension: "5", ISDNSubaddress: "1411,;x"},
+		"TEL:+44-20;tsp=a.example;flag;x=%5Ba%5D/:$": {Number: "+44-20", Params: []TelParam{{"tsp", "a.example", true}, {"flag", "", false}, {"x", "[a]/:$", true}}},
+		"tel:AB-CD;phone-context=+1":                 {Number: "AB-CD", PhoneContext: "+1"},
+	}
+
+	for v, e := range set {
+		tel := mustParse(t, v, ParseTelURI)
+		if !reflect.DeepEqual(*tel, e) {
+			t.Fatalf("unexpected tel uri for '%s': %+v", v, tel)
+		}
+	}
+	if tel := mustParse(t, "tel:+1-201-555-0123", ParseTelURI); !tel.IsGlobal() || tel.Digits() != "+12015550123" {
+		t.Fatalf("unexpected global number %+v", tel)
+	}
+	if tel := mustParse(t, "tel:7042;phone-context=example.com;ext=1", ParseTelURI); tel.IsGlobal() || tel.Digits() != "7042" {
+		t.Fatalf("unexpected local number %+v", tel)
+	} else if v, ok := tel.Param("Phone-Context"); !ok || v != "example.com" {
+		t.Fatalf("phone-context should be found by Param")
+	}
+
+	failSet := []string{
+		"http://example.org/",
+		"tel://example.org/+1",
+		"tel:",
+		"tel:+",
+		"tel:+-.",
+		"tel:+1%20555",
+		"tel:+1A",
+		"tel:1234",
+		"tel:+1234;phone-context=example.com",
+		"tel:1234;phone-context=",
+		"tel:1234;phone-context=-example.com",
+		"tel:1234;phone-context=example.1com",
+		"tel:1234;phone-context=a.com;phone-context=b.com",
+		"tel:+1234;ext=1;ext=2",
+		"tel:+1234;ext=a",
+		"tel:+1234;ext",
+		"tel:+1234;isub",
+		"tel:+1234;x_y=1",
+		"tel:+1234;x=",
+		"tel:+1234;x=a,b",
+		"tel:+1234;x=%FF",
+		"tel:+1234?q",
+		"tel:+1234#f",
+	}
+
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			t.Fatalf("ParseIri should succeed with '%s': %s", v, err.Error())
+		}
+		if _, err := ParseTelURI(iri); err == nil {
+			t.Fatalf("ParseTelURI should fail with '%s'", v)
+		}
+	}
+}
+
+func TestTelURIString(t *testing.T) {
+	set := map[*TelURI]string{
+		{Number: "+1-201-555-0123"}:                   "tel:+1-201-555-0123",
+		{Number: "*21#", PhoneContext: "example.com"}: "tel:*21%23;phone-context=example.com",
+		{Number: "+1", Extension: "12", Params: []TelParam{{"a", "[b] c", true}, {"z", "", false}}}: "tel:+1;ext=12;a=%5Bb%5D%20c;z",
+		{Number: "+1", ISDNSubaddress: "x;y"}:                                                       "tel:+1;isub=x%3By",
+	}
+
+	for tel, e := range set {
+		iri, err := tel.IRI()
+		if err != nil {
+			t.Fatalf("IRI should succeed for %+v: %s", tel, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("%+v should print as '%s', got '%s'", tel, e, iri.Value)
+		}
+		if parsed := mustParse(t, iri.Value, ParseTelURI); !reflect.DeepEqual(parsed, tel) {
+			t.Fatalf("'%s' should round trip to %+v, got %+v", iri.Value, tel, parsed)
+		}
+	}
+
+	failSet := []*TelURI{
+		{Number: "1234"},
+		{Number: "+1", PhoneContext: "example.com"},
+		{Number: "+1", Extension: "x"},
+	}
+	for _, tel := range failSet {
+		if _, err := tel.IRI(); err == nil {
+			t.Fatalf("IRI should fail for %+v", tel)
+		}
+	}
+}
+
+func TestTelURIEquivalent(t *testing.T) {
+	equalSet := [][2]string{
+		{"tel:+1-201-555-0123", "tel:+1(201)555.0123"},
+		{"tel:+1-201-555-0123", "TEL:+12015550123"},
+		{"tel:+1234;ext=1-2;tsp=a.example", "tel:+1234;TSP=A.example;EXT=12"},
+		{"tel:7042;phone-context=Example.COM", "tel:7042;phone-context=example.com"},
+		{"tel:7042;phone-context=example.com.", "tel:7042;phone-context=example.com"},
+		{"tel:863-1234;phone-context=+1-914-555", "tel:8631234;phone-context=+1914555"},
+		{"tel:ab-cd;phone-context=+1", "tel:ABCD;phone-context=+1"},
+		{"tel:+1;x=%41", "tel:+1;x=a"},
+	}
+	differentSet := [][2]string{
+		{"tel:+1234", "tel:+12345"},
+		{"tel:+1234", "tel:1234;phone-context=+1"},
+		{"tel:+1234", "tel:+1234;ext=1"},
+		{"tel:+1234;ext=1", "tel:+1234;ext=2"},
+		{"tel:+1234;x", "tel:+1234;x=1"},
+		{"tel:+1234;x=1;x=1", "tel:+1234;x=1"},
+		{"tel:7042;phone-context=a.example", "tel:7042;phone-context=b.example"},
+	}
+
+	for _, pair := range equalSet {
+		if !mustParse(t, pair[0], ParseTelURI).Equivalent(mustParse(t, pair[1], ParseTelURI)) {
+			t.Fatalf("'%s' and '%s' should be equivalent", pair[0], pair[1])
+		}
+	}
+	for _, pair := range differentSet {
+		if mustParse(t, pair[0], ParseTelURI).Equivalent(mustParse(t, pair[1], ParseTelURI)) {
+			t.Fatalf("'%s' and '%s' should differ", pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,802 @@
+package odin_iri
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Template is a parsed IRI Template (RFC 6570) supporting all four levels.
+type Template struct {
+	raw   string
+	parts []templatePart
+}
+
+// templatePart is either a literal, when operator is nil, or an expression.
+type templatePart struct {
+	literal   string
+	operator  *templateOperator
+	variables []templateVariable
+}
+
+type templateVariable struct {
+	name    string
+	prefix  int
+	explode bool
+}
+
+// templateOperator holds the expansion behaviour of an operator, RFC 6570 appendix A.
+type templateOperator struct {
+	op       byte
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var templateOperators = map[byte]*templateOperator{
+	0:   {op: 0, first: "", sep: ","},
+	'+': {op: '+', first: "", sep: ",", reserved: true},
+	'#': {op: '#', first: "#", sep: ",", reserved: true},
+	'.': {op: '.', first: ".", sep: "."},
+	'/': {op: '/', first: "/", sep: "/"},
+	';': {op: ';', first: ";", sep: ";", named: true},
+	'?': {op: '?', first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {op: '&', first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// ParseTemplate parses an IRI Template. Literals may hold any character the IRI grammar allows
+// outside of '{' and '}', and expressions hold an optional operator followed by a comma separated
+// list of variables, each with an optional ":" prefix length or "*" explode modifier.
+func ParseTemplate(template string) (*Template, error) {
+	t := &Template{raw: template, parts: make([]templatePart, 0)}
+	for rest := template; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			open = len(rest)
+		}
+		if open > 0 {
+			literal := rest[:open]
+			if !isTemplateLiteral(literal) {
+				return nil, errors.New("invalid character in template literal")
+			}
+			t.parts = append(t.parts, templatePart{literal: literal})
+			rest = rest[open:]
+			continue
+		}
+		if rest[0] == '}' {
+			return nil, errors.New("unexpected '}' in template")
+		}
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return nil, errors.New("unclosed template expression")
+		}
+		part, err := parseTemplateExpression(rest[1:end])
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, part)
+		rest = rest[end+1:]
+	}
+	return t, nil
+}
+
+func parseTemplateExpression(expression string) (templatePart, error) {
+	operator := templateOperators[0]
+	if expression != "" {
+		if op, ok := templateOperators[expression[0]]; ok && expression[0] != 0 {
+			operator = op
+			expression = expression[1:]
+		} else if strings.ContainsRune("=,!@|", rune(expression[0])) {
+			return templatePart{}, errors.New("reserved template operator")
+		}
+	}
+	part := templatePart{operator: operator}
+	for _, spec := range strings.Split(expression, ",") {
+		variable := templateVariable{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			variable.name, variable.explode = spec[:len(spec)-1], true
+		} else if name, length, ok := strings.Cut(spec, ":"); ok {
+			prefix, err := strconv.Atoi(length)
+			if err != nil || length[0] == '0' || prefix > 9999 {
+				return templatePart{}, errors.New("invalid template prefix length")
+			}
+			variable.name, variable.prefix = name, prefix
+		}
+		if !isTemplateVarName(variable.name) {
+			return templatePart{}, errors.New("invalid template variable name")
+		}
+		part.variables = append(part.variables, variable)
+	}
+	return part, nil
+}
+
+// String returns the template as it was parsed.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the variables of the template in order of appearance, without
+// duplicates.
+func (t *Template) Variables() []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, part := range t.parts {
+		for _, variable := range part.variables {
+			if !seen[variable.name] {
+				seen[variable.name] = true
+				names = append(names, variable.name)
+			}
+		}
+	}
+	return names
+}
+
+// Expand substitutes vars into the template and parses the result as an IRI reference. A variable
+// is undefined when it is missing, nil, or an empty list or map. Lists are given as slices or
+// arrays, associative arrays as maps, expanded in key order, or as [][2]string pairs to keep their
+// own order. Any other value is formatted with fmt.Sprint. Characters outside the set an
+// expression allows are pct-encoded, except ucschar characters, which the IRI grammar allows
+// everywhere the expansion may end up.
+func (t *Template) Expand(vars map[string]any) (*IRI, error) {
+	b := strings.Builder{}
+	for _, part := range t.parts {
+		if part.operator == nil {
+			b.WriteString(encodeTemplateValue(part.literal, true))
+			continue
+		}
+		if err := part.expand(&b, vars); err != nil {
+			return nil, err
+		}
+	}
+	return ParseIriReference(b.String())
+}
+
+func (p templatePart) expand(b *strings.Builder, vars map[string]any) error {
+	op := p.operator
+	first := true
+	for _, variable := range p.variables {
+		value, defined := templateValueOf(vars[variable.name])
+		if !defined {
+			continue
+		}
+		if first {
+			b.WriteString(op.first)
+			first = false
+		} else {
+			b.WriteString(op.sep)
+		}
+		switch v := value.(type) {
+		case string:
+			if op.named {
+				b.WriteString(variable.name)
+				if v == "" {
+					b.WriteString(op.ifEmpty)
+					continue
+				}
+				b.WriteByte('=')
+			}
+			if variable.prefix > 0 && utf8.RuneCountInString(v) > variable.prefix {
+				v = string([]rune(v)[:variable.prefix])
+			}
+			b.WriteString(encodeTemplateValue(v, op.reserved))
+		case []string:
+			if variable.prefix > 0 {
+				return errors.New("a prefix modifier cannot be applied to a list")
+			}
+			p.expandList(b, variable, v)
+		case [][2]string:
+			if variable.prefix > 0 {
+				return errors.New("a prefix modifier cannot be applied to an associative array")
+			}
+			p.expandPairs(b, variable, v)
+		}
+	}
+	return nil
+}
+
+func (p templatePart) expandList(b *strings.Builder, variable templateVariable, items []string) {
+	op := p.operator
+	if !variable.explode {
+		if op.named {
+			b.WriteString(variable.name + "=")
+		}
+		for i, item := range items {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(encodeTemplateValue(item, op.reserved))
+		}
+		return
+	}
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		if op.named {
+			b.WriteString(variable.name)
+			if item == "" {
+				b.WriteString(op.ifEmpty)
+				continue
+			}
+			b.WriteByte('=')
+		}
+		b.WriteString(encodeTemplateValue(item, op.reserved))
+	}
+}
+
+func (p templatePart) expandPairs(b *strings.Builder, variable templateVariable, pairs [][2]string) {
+	op := p.operator
+	if !variable.explode {
+		if op.named {
+			b.WriteString(variable.name + "=")
+		}
+		for i, pair := range pairs {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(encodeTemplateValue(pair[0], op.reserved))
+			b.WriteByte(',')
+			b.WriteString(encodeTemplateValue(pair[1], op.reserved))
+		}
+		return
+	}
+	for i, pair := range pairs {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		b.WriteString(encodeTemplateValue(pair[0], op.reserved))
+		if op.named && pair[1] == "" {
+			b.WriteString(op.ifEmpty)
+			continue
+		}
+		b.WriteByte('=')
+		b.WriteString(encodeTemplateValue(pair[1], op.reserved))
+	}
+}
+
+// templateValueOf converts a variable value into a string, a list as []string or an associative
+// array as [][2]string, reporting whether the variable is defined.
+func templateValueOf(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, true
+	case [][2]string:
+		return v, len(v) > 0
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() || rv.Len() == 0 {
+			return nil, false
+		}
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return items, true
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return nil, false
+		}
+		pairs := make([][2]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			pairs = append(pairs, [2]string{fmt.Sprint(key.Interface()), fmt.Sprint(rv.MapIndex(key).Interface())})
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i][0] < pairs[j][0]
+		})
+		return pairs, true
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, false
+		}
+		return templateValueOf(rv.Elem().Interface())
+	}
+	return fmt.Sprint(value), true
+}
+
+// encodeTemplateValue pct-encodes everything but unreserved and ucschar characters, and also keeps
+// reserved characters and pct-encoded triples when reserved expansion is allowed.
+func encodeTemplateValue(value string, reserved bool) string {
+	if !reserved {
+		return pctEncode(value, func(r rune) bool {
+			return isUnreserved(r) || isUcsChar(r)
+		})
+	}
+	b := strings.Builder{}
+	for i := 0; i < len(value); {
+		if value[i] == '%' && i+2 < len(value) && isHexDigit(rune(value[i+1])) && isHexDigit(rune(value[i+2])) {
+			b.WriteString(value[i : i+3])
+			i += 3
+			continue
+		}
+		end := strings.IndexByte(value[i+1:], '%')
+		if end < 0 {
+			end = len(value)
+		} else {
+			end += i + 1
+		}
+		b.WriteString(pctEncode(value[i:end], func(r rune) bool {
+			return isUnreserved(r) || isReserved(r) || isUcsChar(r)
+		}))
+		i = end
+	}
+	return b.String()
+}
+
+// isTemplateLiteral checks the literals production, which excludes controls, space, quotation
+// marks and apostrophes, '%' outside of a pct-encoded triple, '<', '>', '\\', '^', '`', '{', '|',
+// '}' and non-ASCII characters other than ucschar and iprivate.
+func isTemplateLiteral(literal string) bool {
+	for i, r := range literal {
+		switch {
+		case r == '%':
+			if i+2 >= len(literal) || !isHexDigit(rune(literal[i+1])) || !isHexDigit(rune(literal[i+2])) {
+				return false
+			}
+		case r <= ' ' || r == 0x7f || strings.ContainsRune("\"'<>\\^`{|}", r):
+			return false
+		case r >= utf8.RuneSelf && !isUcsChar(r) && !isIPrivate(r):
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplateVarName checks varchar *( ["."] varchar ), varchar being ALPHA, DIGIT, '_' or a
+// pct-encoded triple.
+func isTemplateVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || strings.Contains(name, "..") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '%':
+			if i+2 >= len(name) || !isHexDigit(rune(name[i+1])) || !isHexDigit(rune(name[i+2])) {
+				return false
+			}
+			i += 2
+		case !isAlpha(rune(c)) && !isDigit(rune(c)) && c != '_' && c != '.':
+			return false
+		}
+	}
+	return true
+}
+
+// Match extracts the variables of the template from an IRI, the inverse of Expand. The template
+// must match the whole of the IRI's Value; literals are compared exactly and values are decoded.
+// Strings come back as string, exploded lists as []string and exploded associative arrays as
+// map[string]string.
+//
+// Expansion loses information, so some cases are ambiguous and are resolved as follows:
+//   - Each expression takes the shortest text that lets the rest of the template match. Text that
+//     holds characters the expression would have pct-encoded is never taken.
+//   - A list that is not exploded comes back as a single string of its comma joined items, since
+//     nothing tells it apart from a string holding commas.
+//   - In ';', '?' and '&' expressions variables are found by name in any order, and names that
+//     are not variables of the expression go to its exploded variable, failing without one.
+//   - In the other expressions undefined variables are skipped without a trace, so the values
+//     are given to the variables in order and the trailing ones are left undefined. An exploded
+//     variable takes every extra item, and is read as an associative array when all of its items
+//     hold a '='. Only one exploded variable per expression is supported. A bare operator prefix,
+//     such as the "/" that "{/id}" expands an empty string to, gives the first variable "".
+//   - Reserved expansions ('+' and '#') copy ',' and pct-encoded triples from their values, so a
+//     ',' always separates values and pct-encoded triples are always decoded.
+//   - A variable with a prefix modifier only needs to be a prefix of the other occurrences of the
+//     same variable, which must otherwise all be equal.
+//   - An expression directly following another one must start with an operator prefix such as
+//     '/' or '?', otherwise nothing tells where the first one ends and the template never
+//     matches. "{a}{b}" and "{/a}{b}" are rejected, while "{/a}{?b}" is not.
+func (t *Template) Match(iri *IRI) (map[string]any, bool) {
+	for i := 1; i < len(t.parts); i++ {
+		if t.parts[i-1].operator != nil && t.parts[i].operator != nil && t.parts[i].operator.first == "" {
+			return nil, false
+		}
+	}
+	m := newTemplateMatcher(t.parts, iri.Value)
+	state := &templateMatch{vars: make(map[string]any), partial: make(map[string]bool)}
+	if !m.match(0, 0, state) {
+		return nil, false
+	}
+	return state.vars, true
+}
+
+// templateMatch holds the variables found so far, partial marking those only known from an
+// expression with a prefix modifier.
+type templateMatch struct {
+	vars    map[string]any
+	partial map[string]bool
+}
+
+func (m *templateMatch) clone() *templateMatch {
+	c := &templateMatch{vars: make(map[string]any, len(m.vars)), partial: make(map[string]bool, len(m.partial))}
+	for k, v := range m.vars {
+		c.vars[k] = v
+	}
+	for k, v := range m.partial {
+		c.partial[k] = v
+	}
+	return c
+}
+
+// assign records a value, checking it against earlier occurrences of the same variable.
+func (m *templateMatch) assign(variable templateVariable, value any) bool {
+	s, isString := value.(string)
+	if variable.prefix > 0 && (!isString || utf8.RuneCountInString(s) > variable.prefix) {
+		return false
+	}
+	previous, ok := m.vars[variable.name]
+	if !ok {
+		m.vars[variable.name] = value
+		m.partial[variable.name] = variable.prefix > 0
+		return true
+	}
+	p, previousIsString := previous.(string)
+	switch {
+	case isString && previousIsString && variable.prefix > 0:
+		return strings.HasPrefix(p, s)
+	case isString && previousIsString && m.partial[variable.name]:
+		m.vars[variable.name], m.partial[variable.name] = value, false
+		return strings.HasPrefix(s, p)
+	}
+	return reflect.DeepEqual(previous, value)
+}
+
+// templateMatcher matches the parts of a template against value, remembering the states from
+// which the rest of the template is known not to match so that each of them is only tried once.
+type templateMatcher struct {
+	parts []templatePart
+	value string
+	// carried holds, for each part, the variables found before it that it or a later part uses
+	// again, as matching the rest of the template only depends on the offset and on those.
+	carried [][]string
+	// independent marks the expressions none of whose variables are used by a later part.
+	independent []bool
+	failed      map[templateMatchKey]bool
+}
+
+type templateMatchKey struct {
+	index, offset int
+	carried       string
+}
+
+func newTemplateMatcher(parts []templatePart, value string) *templateMatcher {
+	m := &templateMatcher{
+		parts:       parts,
+		value:       value,
+		carried:     make([][]string, len(parts)+1),
+		independent: make([]bool, len(parts)),
+		failed:      make(map[templateMatchKey]bool),
+	}
+	firstUse, lastUse := make(map[string]int), make(map[string]int)
+	for i, part := range parts {
+		for _, variable := range part.variables {
+			if _, ok := firstUse[variable.name]; !ok {
+				firstUse[variable.name] = i
+			}
+			lastUse[variable.name] = i
+		}
+	}
+	for name, first := range firstUse {
+		for i := first + 1; i <= lastUse[name]; i++ {
+			m.carried[i] = append(m.carried[i], name)
+		}
+	}
+	for i, part := range parts {
+		sort.Strings(m.carried[i])
+		m.independent[i] = true
+		for _, variable := range part.variables {
+			if lastUse[variable.name] > i {
+				m.independent[i] = false
+			}
+		}
+	}
+	return m
+}
+
+// key identifies the state of matching the parts from index on at offset.
+func (m *templateMatcher) key(index, offset int, state *templateMatch) templateMatchKey {
+	key := templateMatchKey{index: index, offset: offset}
+	if len(m.carried[index]) > 0 {
+		b := strings.Builder{}
+		for _, name := range m.carried[index] {
+			fmt.Fprintf(&b, "%q=%#v,%t;", name, state.vars[name], state.partial[name])
+		}
+		key.carried = b.String()
+	}
+	return key
+}
+
+func (m *templateMatcher) match(index, offset int, state *templateMatch) bool {
+	if len(m.parts) == index {
+		return offset == len(m.value)
+	}
+	key := m.key(index, offset, state)
+	if m.failed[key] {
+		return false
+	}
+	if m.matchPart(index, offset, state) {
+		return true
+	}
+	m.failed[key] = true
+	return false
+}
+
+func (m *templateMatcher) matchPart(index, offset int, state *templateMatch) bool {
+	rest := m.value[offset:]
+	part := m.parts[index]
+	if part.operator == nil {
+		literal := encodeTemplateValue(part.literal, true)
+		return strings.HasPrefix(rest, literal) && m.match(index+1, offset+len(literal), state)
+	}
+	limit := 0
+	if op := part.operator; strings.HasPrefix(rest, op.first) {
+		limit = len(op.first) + part.expansionLength(rest[len(op.first):])
+	}
+	followers, canEnd := m.followers(index)
+	next := m.key(index+1, offset, state)
+	for end := 0; end <= limit; end++ {
+		if !(canEnd && end == len(rest)) && !hasAnyPrefix(rest[end:], followers) {
+			continue
+		}
+		if m.independent[index] {
+			// The rest of the template does not use the variables of this expression, so it is
+			// matched first and a failure is remembered for every other start of the expression.
+			next.offset = offset + end
+			if m.failed[next] {
+				continue
+			}
+			candidate := state.clone()
+			if m.match(index+1, offset+end, candidate) && part.match(rest[:end], candidate) {
+				*state = *candidate
+				return true
+			}
+			continue
+		}
+		candidate := state.clone()
+		if part.match(rest[:end], candidate) && m.match(index+1, offset+end, candidate) {
+			*state = *candidate
+			return true
+		}
+	}
+	return false
+}
+
+// followers returns the text that may directly follow the expression at index: the operator
+// prefixes of the expressions after it up to the next literal, which may all be empty, and that
+// literal. canEnd reports whether nothing but empty expressions follows.
+func (m *templateMatcher) followers(index int) (followers []string, canEnd bool) {
+	for _, part := range m.parts[index+1:] {
+		if part.operator == nil {
+			return append(followers, encodeTemplateValue(part.literal, true)), false
+		}
+		followers = append(followers, part.operator.first)
+	}
+	return followers, true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reads the expansion of the expression from text.
+func (p templatePart) match(text string, state *templateMatch) bool {
+	op := p.operator
+	if text == "" {
+		return true
+	}
+	if !strings.HasPrefix(text, op.first) {
+		return false
+	}
+	// An empty string expands to the bare prefix of an unnamed operator, while named operators
+	// always write "name=".
+	if op.named && len(text) == len(op.first) {
+		return false
+	}
+	body := text[len(op.first):]
+	if !op.reserved && !isTemplateExpansion(body, op.sep) {
+		return false
+	}
+	if op.named {
+		return p.matchNamed(strings.Split(body, op.sep), state)
+	}
+	if len(p.variables) == 1 && !p.variables[0].explode && op.sep == "," {
+		decoded, err := Decode(body)
+		return err == nil && state.assign(p.variables[0], decoded)
+	}
+	return p.matchUnnamed(strings.Split(body, op.sep), state)
+}
+
+func (p templatePart) matchNamed(pieces []string, state *templateMatch) bool {
+	values := make(map[string]any)
+	var exploded *templateVariable
+	for v, variable := range p.variables {
+		if variable.explode && exploded == nil {
+			exploded = &p.variables[v]
+		}
+	}
+	for _, piece := range pieces {
+		rawName, rawValue, _ := strings.Cut(piece, "=")
+		name, err := Decode(rawName)
+		if err != nil {
+			return false
+		}
+		value, err := Decode(rawValue)
+		if err != nil {
+			return false
+		}
+		variable, found := p.variable(name)
+		switch {
+		case found && !variable.explode:
+			if _, ok := values[name]; ok {
+				return false
+			}
+			values[name] = value
+		case found:
+			list, ok := values[name].([]string)
+			if _, assigned := values[name]; assigned && !ok {
+				return false
+			}
+			values[name] = append(list, value)
+		case exploded != nil:
+			pairs, ok := values[exploded.name].(map[string]string)
+			if _, assigned := values[exploded.name]; assigned && !ok {
+				return false
+			}
+			if pairs == nil {
+				pairs = make(map[string]string)
+			}
+			pairs[name] = value
+			values[exploded.name] = pairs
+		default:
+			return false
+		}
+	}
+	for _, variable := range p.variables {
+		if value, ok := values[variable.name]; ok && !state.assign(variable, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p templatePart) matchUnnamed(pieces []string, state *templateMatch) bool {
+	explodeCount := 0
+	for _, variable := range p.variables {
+		if variable.explode {
+			explodeCount++
+		}
+	}
+	if explodeCount > 1 || explodeCount == 0 && len(pieces) > len(p.variables) {
+		return false
+	}
+	extra := len(pieces) - len(p.variables) + 1
+	for _, variable := range p.variables {
+		if len(pieces) == 0 {
+			break
+		}
+		if !variable.explode {
+			decoded, err := Decode(pieces[0])
+			if err != nil || !state.assign(variable, decoded) {
+				return false
+			}
+			pieces = pieces[1:]
+			continue
+		}
+		count := extra
+		if count < 1 {
+			count = 1
+		}
+		value, ok := explodedValue(pieces[:count])
+		if !ok || !state.assign(variable, value) {
+			return false
+		}
+		pieces = pieces[count:]
+	}
+	return true
+}
+
+// explodedValue decodes the items of an exploded variable, which form an associative array when
+// all of them hold a '='.
+func explodedValue(items []string) (any, bool) {
+	pairs := make(map[string]string)
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		decoded, err := Decode(item)
+		if err != nil {
+			return nil, false
+		}
+		list = append(list, decoded)
+		if pairs == nil {
+			continue
+		}
+		rawKey, rawValue, ok := strings.Cut(item, "=")
+		if !ok {
+			pairs = nil
+			continue
+		}
+		key, keyErr := Decode(rawKey)
+		value, valueErr := Decode(rawValue)
+		if keyErr != nil || valueErr != nil {
+			return nil, false
+		}
+		pairs[key] = value
+	}
+	if pairs != nil {
+		return pairs, true
+	}
+	return list, true
+}
+
+// isTemplateExpansion reports whether text can be the expansion of a non-reserved expression:
+// unreserved and ucschar characters, pct-encoded triples, the separator and the ',' and '=' that
+// join lists and associative arrays.
+func isTemplateExpansion(text string, sep string) bool {
+	for i, r := range text {
+		switch {
+		case r == '%':
+			if i+2 >= len(text) || !isHexDigit(rune(text[i+1])) || !isHexDigit(rune(text[i+2])) {
+				return false
+			}
+		case !isUnreserved(r) && !isUcsChar(r) && r != ',' && r != '=' && !strings.ContainsRune(sep, r):
+			return false
+		}
+	}
+	return true
+}
+
+// expansionLength returns the length of the longest prefix of text, which starts after the
+// operator prefix, that the expression may expand to: up to the first malformed pct-encoded triple
+// and, for non-reserved expressions, up to the first character isTemplateExpansion rejects. Without
+// an exploded variable each variable gives at most one item, which bounds the separators unless
+// they are the ',' that also joins lists.
+func (p templatePart) expansionLength(text string) int {
+	op := p.operator
+	separators := -1
+	if op.sep != "," {
+		separators = len(p.variables) - 1
+		for _, variable := range p.variables {
+			if variable.explode {
+				separators = -1
+			}
+		}
+	}
+	for i, r := range text {
+		switch {
+		case r == '%':
+			if i+2 >= len(text) || !isHexDigit(rune(text[i+1])) || !isHexDigit(rune(text[i+2])) {
+				return i
+			}
+		case strings.ContainsRune(op.sep, r):
+			if separators == 0 {
+				return i
+			}
+			separators--
+		case !op.reserved && !isUnreserved(r) && !isUcsChar(r) && r != ',' && r != '=':
+			return i
+		}
+	}
+	return len(text)
+}
+
+func (p templatePart) variable(name string) (templateVariable, bool) {
+	for _, variable := range p.variables {
+		if variable.name == name {
+			return variable, true
+		}
+	}
+	return templateVariable{}, false
+}
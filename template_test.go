@@ -0,0 +1,371 @@
+package odin_iri
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// templateVars are the example variables of RFC 6570 section 3.2.
+var templateVars = map[string]any{
+	"count":      []string{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []string{"red", "green", "blue"},
+	"keys":       [][2]string{{"semi", ";"}, {"dot", "."}, {"comma", ","}},
+	"v":          "6",
+	"x":          "1024",
+	"y":          "768",
+	"empty":      "",
+	"empty_keys": map[string]string{},
+	"undef":      nil,
+}
+
+func TestTemplateExpand(t *testing.T) {
+	set := map[string]string{
+		// Level 1.
+		"{var}":   "value",
+		"{hello}": "Hello%20World%21",
+		// Level 2.
+		"{+var}":           "value",
+		"{+hello}":         "Hello%20World!",
+		"{+path}/here":     "/foo/bar/here",
+		"here?ref={+path}": "here?ref=/foo/bar",
+		"X{#var}":          "X#value",
+		"X{#hello}":        "X#Hello%20World!",
+		"{+half}":          "50%25",
+		"{half}":           "50%25",
+		// Level 3.
+		"map?{x,y}":           "map?1024,768",
+		"{x,hello,y}":         "1024,Hello%20World%21,768",
+		"{+x,hello,y}":        "1024,Hello%20World!,768",
+		"{+path,x}/here":      "/foo/bar,1024/here",
+		"{#x,hello,y}":        "#1024,Hello%20World!,768",
+		"{#path,x}/here":      "#/foo/bar,1024/here",
+		"X{.var}":             "X.value",
+		"X{.x,y}":             "X.1024.768",
+		"{/var}":              "/value",
+		"{/var,x}/here":       "/value/1024/here",
+		"{;x,y}":              ";x=1024;y=768",
+		"{;x,y,empty}":        ";x=1024;y=768;empty",
+		"{?x,y}":              "?x=1024&y=768",
+		"{?x,y,empty}":        "?x=1024&y=768&empty=",
+		"?fixed=yes{&x}":      "?fixed=yes&x=1024",
+		"{&x,y,empty}":        "&x=1024&y=768&empty=",
+		"{var,undef,empty}":   "value,",
+		"{?undef,empty_keys}": "",
+		// Level 4.
+		"{var:3}":         "val",
+		"{var:30}":        "value",
+		"{list}":          "red,green,blue",
+		"{list*}":         "red,green,blue",
+		"{keys}":          "semi,%3B,dot,.,comma,%2C",
+		"{keys*}":         "semi=%3B,dot=.,comma=%2C",
+		"{+path:6}/here":  "/foo/b/here",
+		"{+list}":         "red,green,blue",
+		"{+list*}":        "red,green,blue",
+		"{+keys}":         "semi,;,dot,.,comma,,",
+		"{+keys*}":        "semi=;,dot=.,comma=,",
+		"{#path:6}/here":  "#/foo/b/here",
+		"{#list}":         "#red,green,blue",
+		"{#list*}":        "#red,green,blue",
+		"{#keys}":         "#semi,;,dot,.,comma,,",
+		"{#keys*}":        "#semi=;,dot=.,comma=,",
+		"X{.var:3}":       "X.val",
+		"X{.list}":        "X.red,green,blue",
+		"X{.list*}":       "X.red.green.blue",
+		"X{.keys}":        "X.semi,%3B,dot,.,comma,%2C",
+		"X{.keys*}":       "X.semi=%3B.dot=..comma=%2C",
+		"{/var:1,var}":    "/v/value",
+		"{/list}":         "/red,green,blue",
+		"{/list*}":        "/red/green/blue",
+		"{/list*,path:4}": "/red/green/blue/%2Ffoo",
+		"{/keys}":         "/semi,%3B,dot,.,comma,%2C",
+		"{/keys*}":        "/semi=%3B/dot=./comma=%2C",
+		"{;hello:5}":      ";hello=Hello",
+		"{;list}":         ";list=red,green,blue",
+		"{;list*}":        ";list=red;list=green;list=blue",
+		"{;keys}":         ";keys=semi,%3B,dot,.,comma,%2C",
+		"{;keys*}":        ";semi=%3B;dot=.;comma=%2C",
+		"{?var:3}":        "?var=val",
+		"{?list}":         "?list=red,green,blue",
+		"{?list*}":        "?list=red&list=green&list=blue",
+		"{?keys}":         "?keys=semi,%3B,dot,.,comma,%2C",
+		"{?keys*}":        "?semi=%3B&dot=.&comma=%2C",
+		"{&var:3}":        "&var=val",
+		"{&list}":         "&list=red,green,blue",
+		"{&list*}":        "&list=red&list=green&list=blue",
+		"{&keys}":         "&keys=semi,%3B,dot,.,comma,%2C",
+		"{&keys*}":        "&semi=%3B&dot=.&comma=%2C",
+		"{.dom*}":         ".example.com",
+		"{count}":         "one,two,three",
+		"{/count*}":       "/one/two/three",
+		"{;count*}":       ";count=one;count=two;count=three",
+		"{+base}index":    "http://example.com/home/index",
+		"{dub}":           "me%2Ftoo",
+		"{+dub}":          "me/too",
+		"{var}{?who}{#x}": "value?who=fred#1024",
+		// Unicode stays unescaped wherever the IRI grammar allows it.
+		"/ü/{uni}":  "/ü/Grüße%2F日本",
+		"{+uni}":    "Grüße/日本",
+		"{?uni}":    "?uni=Grüße%2F日本",
+		"{uni:3}":   "Grü",
+		"{private}": "%EE%80%80",
+	}
+
+	vars := map[string]any{"uni": "Grüße/日本", "private": "\ue000"}
+	for k, v := range templateVars {
+		vars[k] = v
+	}
+	for template, e := range set {
+		tmpl, err := ParseTemplate(template)
+		if err != nil {
+			t.Fatalf("ParseTemplate should succeed with '%s': %s", template, err.Error())
+		}
+		iri, err := tmpl.Expand(vars)
+		if err != nil {
+			t.Fatalf("expanding '%s' should succeed: %s", template, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("'%s' should expand to '%s', got '%s'", template, e, iri.Value)
+		}
+	}
+}
+
+func TestTemplateValues(t *testing.T) {
+	set := map[string]map[string]any{
+		"1,2.5,true": {"a": 1, "b": 2.5, "c": true},
+		"a,1,b,2":    {"a": map[string]int{"b": 2, "a": 1}},
+		"x,y":        {"a": []any{"x", "y"}},
+		"x,y,z":      {"a": [3]string{"x", "y", "z"}},
+		"p":          {"a": func() *string { s := "p"; return &s }()},
+		"":           {"a": []string{}, "b": (*string)(nil)},
+	}
+
+	tmpl, err := ParseTemplate("{a,b,c}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e, vars := range set {
+		iri, err := tmpl.Expand(vars)
+		if err != nil {
+			t.Fatalf("expanding %v should succeed: %s", vars, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("%v should expand to '%s', got '%s'", vars, e, iri.Value)
+		}
+	}
+
+	if _, err := tmpl.Expand(map[string]any{"a": "http://a b"}); err != nil {
+		t.Fatalf("unreserved expansion escapes everything: %s", err.Error())
+	}
+	bad, _ := ParseTemplate("{+a}")
+	if _, err := bad.Expand(map[string]any{"a": "1:b"}); err == nil {
+		t.Fatalf("a reserved expansion producing an invalid iri reference should fail")
+	}
+	prefix, _ := ParseTemplate("{a:2}")
+	if _, err := prefix.Expand(map[string]any{"a": []string{"x"}}); err == nil {
+		t.Fatalf("a prefix on a list should fail")
+	}
+	if _, err := prefix.Expand(map[string]any{"a": map[string]string{"x": "y"}}); err == nil {
+		t.Fatalf("a prefix on an associative array should fail")
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("/users/{id}/posts{?page,limit}{&id}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := tmpl.Variables(); !reflect.DeepEqual(v, []string{"id", "page", "limit"}) {
+		t.Fatalf("unexpected variables %v", v)
+	}
+	if tmpl.String() != "/users/{id}/posts{?page,limit}{&id}" {
+		t.Fatalf("String should return the template")
+	}
+
+	goodSet := []string{
+		"",
+		"no/expressions",
+		"{a.b}",
+		"{a_b,c%20d}",
+		"{a:9999}",
+		"ü{a}%20",
+	}
+	failSet := []string{
+		"{",
+		"}",
+		"{a",
+		"a}",
+		"{}",
+		"{a,}",
+		"{,a}",
+		"{=a}",
+		"{!a}",
+		"{@a}",
+		"{|a}",
+		"{a b}",
+		"{.a.}",
+		"{a..b}",
+		"{a:0}",
+		"{a:01}",
+		"{a:10000}",
+		"{a:}",
+		"{a:1*}",
+		"{a%2}",
+		"a b",
+		"a%2",
+		"a<b",
+		"a\"b",
+		"a|b",
+		"{a{b}}",
+	}
+
+	for _, v := range goodSet {
+		if _, err := ParseTemplate(v); err != nil {
+			t.Fatalf("ParseTemplate should succeed with '%s': %s", v, err.Error())
+		}
+	}
+	for _, v := range failSet {
+		if _, err := ParseTemplate(v); err == nil {
+			t.Fatalf("ParseTemplate should fail with '%s'", v)
+		}
+	}
+}
+
+func TestTemplateMatch(t *testing.T) {
+	type test struct {
+		template string
+		value    string
+		vars     map[string]any
+	}
+	set := []test{
+		{"/users/{id}/posts{?page,limit}", "/users/42/posts?page=2&limit=10", map[string]any{"id": "42", "page": "2", "limit": "10"}},
+		{"/users/{id}/posts{?page,limit}", "/users/42/posts?limit=10", map[string]any{"id": "42", "limit": "10"}},
+		{"/users/{id}/posts{?page,limit}", "/users/42/posts", map[string]any{"id": "42"}},
+		{"/users/{id}/posts{?page,limit}", "/users/a%20b/posts?page=", map[string]any{"id": "a b", "page": ""}},
+		{"/users/{id}", "/users/Grüße", map[string]any{"id": "Grüße"}},
+		{"http://example.org{/segments*}{?q}", "http://example.org/a/b/c?q=x", map[string]any{"segments": []string{"a", "b", "c"}, "q": "x"}},
+		{"{+base}{/name}{.ext}", "http://example.com/home/index.html", map[string]any{"base": "http://example.com/home", "name": "index", "ext": "html"}},
+		{"{+path}/here", "/foo/bar/here", map[string]any{"path": "/foo/bar"}},
+		{"{x,hello,y}", "1024,Hello%20World%21,768", map[string]any{"x": "1024", "hello": "Hello World!", "y": "768"}},
+		{"{list}", "red,green,blue", map[string]any{"list": "red,green,blue"}},
+		{"{list*}", "red,green,blue", map[string]any{"list": []string{"red", "green", "blue"}}},
+		{"X{.keys*}", "X.semi=%3B.dot=..comma=%2C", nil},
+		{"{/keys*}", "/semi=%3B/dot=./comma=%2C", map[string]any{"keys": map[string]string{"semi": ";", "dot": ".", "comma": ","}}},
+		{"{;x,y,empty}", ";x=1024;y=768;empty", map[string]any{"x": "1024", "y": "768", "empty": ""}},
+		{"{;list*}", ";list=red;list=green;list=blue", map[string]any{"list": []string{"red", "green", "blue"}}},
+		{"{?keys*}", "?semi=%3B&dot=.&comma=%2C", map[string]any{"keys": map[string]string{"semi": ";", "dot": ".", "comma": ","}}},
+		{"{?page,filter*}", "?page=1&color=red&size=m", map[string]any{"page": "1", "filter": map[string]string{"color": "red", "size": "m"}}},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024", map[string]any{"x": "1024"}},
+		{"{/var:1,var}", "/v/value", map[string]any{"var": "value"}},
+		{"{var:3}/{var}", "val/value", map[string]any{"var": "value"}},
+		{"{var,undef}", "value", map[string]any{"var": "value"}},
+		{"{/a,b,c}", "/1/2", map[string]any{"a": "1", "b": "2"}},
+		{"{/a,rest*,z}", "/1/2/3/4", map[string]any{"a": "1", "rest": []string{"2", "3"}, "z": "4"}},
+		{"{#section}", "#intro", map[string]any{"section": "intro"}},
+		{"/static", "/static", map[string]any{}},
+		{"/users{/id}{?fields}", "/users/42?fields=name", map[string]any{"id": "42", "fields": "name"}},
+		{"/users{/id}{?fields}", "/users", map[string]any{}},
+		{"/users{/id}", "/users/", map[string]any{"id": ""}},
+		{"X{.x}", "X.", map[string]any{"x": ""}},
+		{"{#x}", "#", map[string]any{"x": ""}},
+	}
+
+	for _, test := range set {
+		tmpl, err := ParseTemplate(test.template)
+		if err != nil {
+			t.Fatalf("ParseTemplate should succeed with '%s': %s", test.template, err.Error())
+		}
+		iri, err := ParseIriReference(test.value)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", test.value, err.Error())
+		}
+		vars, ok := tmpl.Match(iri)
+		if !ok {
+			t.Fatalf("'%s' should match '%s'", test.template, test.value)
+		}
+		if test.vars == nil {
+			continue
+		}
+		if !reflect.DeepEqual(vars, test.vars) {
+			t.Fatalf("'%s' matching '%s' should give %v, got %v", test.template, test.value, test.vars, vars)
+		}
+		expanded, err := tmpl.Expand(vars)
+		if err != nil {
+			t.Fatalf("expanding %v should succeed: %s", vars, err.Error())
+		}
+		if _, ok := tmpl.Match(expanded); !ok {
+			t.Fatalf("the expansion '%s' of the matched variables should match '%s'", expanded.Value, test.template)
+		}
+	}
+
+	failSet := []test{
+		{"/users/{id}/posts", "/users/42/comments", nil},
+		{"/users/{id}", "/users/4/2", nil},
+		{"/users/{id}", "/accounts/42", nil},
+		{"{?page}", "?size=1", nil},
+		{"{?page}", "?page=1&page=2", nil},
+		{"{?page}", "?", nil},
+		{"{/a,b}", "/1/2/3", nil},
+		{"{var:3}", "value", nil},
+		{"{var:3}/{var}", "val/other", nil},
+		{"{a}/{a}", "x/y", nil},
+		{"{/a*,b*}", "/1/2", nil},
+		{"{a}", "%FF", nil},
+		{"/static", "/static/", nil},
+		{"{a}{b}", "xy", nil},
+		{"{/a}{b}", "/x", nil},
+		{"{a}{+b}", "xy", nil},
+	}
+
+	for _, test := range failSet {
+		tmpl, err := ParseTemplate(test.template)
+		if err != nil {
+			t.Fatalf("ParseTemplate should succeed with '%s': %s", test.template, err.Error())
+		}
+		iri, err := ParseIriReference(test.value)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed with '%s': %s", test.value, err.Error())
+		}
+		if vars, ok := tmpl.Match(iri); ok {
+			t.Fatalf("'%s' should not match '%s', got %v", test.template, test.value, vars)
+		}
+	}
+}
+
+func TestTemplateMatchLargeInput(t *testing.T) {
+	set := map[string]string{
+		"/x{a}{b}{c}{d}/end":       "/x" + strings.Repeat("a", 5000) + "/nope",
+		"/x{a}/{b}/{c}/{d}/end":    "/x" + strings.Repeat("a/", 2500) + "nope",
+		"{/a}{/b}{/c}{/d}/end":     strings.Repeat("/a", 2500) + "/nope",
+		"{+a}{/b}{.c}{?d}/end":     strings.Repeat("/a.b", 1250) + "/nope",
+		"{/a}{/b}{/a}{/b}/end":     strings.Repeat("/a", 500) + "/nope",
+		"/{a}/{b}/{c}{?q,r}{&s}#x": "/" + strings.Repeat("a/", 2500) + "?q=1&s=2#y",
+	}
+
+	for template, value := range set {
+		tmpl, err := ParseTemplate(template)
+		if err != nil {
+			t.Fatalf("ParseTemplate should succeed with '%s': %s", template, err.Error())
+		}
+		iri, err := ParseIriReference(value)
+		if err != nil {
+			t.Fatalf("ParseIriReference should succeed: %s", err.Error())
+		}
+		start := time.Now()
+		if _, ok := tmpl.Match(iri); ok {
+			t.Fatalf("'%s' should not match", template)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("matching '%s' against a large input took %s", template, elapsed)
+		}
+	}
+}
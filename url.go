@@ -0,0 +1,85 @@
+package odin_iri
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// URI maps the IRI onto a URI as described in RFC 3987 section 3.1 by percent-encoding the UTF-8
+// octets of every non-ASCII character. All other characters, including existing pct-encoded
+// triples, are left untouched.
+func (i *IRI) URI() string {
+	return iriToUri(i.Value)
+}
+
+// URL converts the IRI into a net/url URL by mapping it onto a URI and parsing that with
+// url.Parse, so every field is filled exactly as net/url fills it. An error is returned when
+// net/url cannot represent the mapped URI.
+func (i *IRI) URL() (*url.URL, error) {
+	return url.Parse(i.URI())
+}
+
+// FromURL converts a net/url URL back into an IRI following RFC 3987 section 3.2: pct-encoded
+// UTF-8 sequences of characters allowed by the IRI grammar are decoded, everything else stays
+// encoded. Relative URLs produce relative IRI references. FromURL returns nil when u is nil or
+// its string form is not a valid IRI reference.
+func FromURL(u *url.URL) *IRI {
+	if u == nil {
+		return nil
+	}
+	iri, err := ParseIriReference(uriToIri(u.String()), WithZoneID())
+	if err != nil {
+		return nil
+	}
+	return iri
+}
+
+func iriToUri(value string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < utf8.RuneSelf {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// uriToIri decodes runs of pct-encoded octets that form UTF-8 encoded ucschar characters. Octets
+// that decode to ASCII, to invalid UTF-8 or to characters outside ucschar keep their original
+// pct-encoded form so the result always parses to the same resource.
+func uriToIri(value string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(value); {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+		// Collect the run of consecutive pct-encoded triples.
+		start := i
+		octets := make([]byte, 0)
+		for i+2 < len(value) && value[i] == '%' && isHexDigit(rune(value[i+1])) && isHexDigit(rune(value[i+2])) {
+			octets = append(octets, pctDecode(value[i : i+3])[0])
+			i += 3
+		}
+		if len(octets) == 0 {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+		for j := 0; j < len(octets); {
+			r, size := utf8.DecodeRune(octets[j:])
+			if r != utf8.RuneError && isUcsChar(r) {
+				b.WriteRune(r)
+			} else {
+				b.WriteString(value[start+j*3 : start+(j+size)*3])
+			}
+			j += size
+		}
+	}
+	return b.String()
+}
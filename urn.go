@@ -0,0 +1,242 @@
+package odin_iri
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// URN is a Uniform Resource Name (RFC 8141) extracted from an IRI. Every component is kept in the
+// pct-encoded form it has in the IRI.
+type URN struct {
+	// NID is the namespace identifier as written, it is compared case-insensitively.
+	NID string
+	// NSS is the namespace specific string.
+	NSS string
+	// RComponent holds the resolution parameters that followed "?+", when HasR is set.
+	RComponent string
+	HasR       bool
+	// QComponent holds the query parameters that followed "?=", when HasQ is set.
+	QComponent string
+	HasQ       bool
+	// FComponent holds the fragment, when HasF is set.
+	FComponent string
+	HasF       bool
+}
+
+// URNNamespace holds the rules of a formal URN namespace.
+type URNNamespace struct {
+	// Validate, when set, checks the NSS of a URN in the namespace.
+	Validate func(nss string) error
+	// Normalize, when set, rewrites an NSS into the form used to decide lexical equivalence, on
+	// top of the pct-encoding normalization applied to every namespace.
+	Normalize func(nss string) string
+}
+
+var urnNamespaces = struct {
+	sync.RWMutex
+	namespaces map[string]URNNamespace
+}{namespaces: map[string]URNNamespace{
+	"uuid": {Validate: validateUuidNss, Normalize: strings.ToLower},
+	"isbn": {Validate: validateIsbnNss, Normalize: normalizeIsbnNss},
+	"oid":  {Validate: validateOidNss},
+	"ietf": {Validate: validateIetfNss, Normalize: strings.ToLower},
+}}
+
+// RegisterURNNamespace adds or replaces the rules of the namespace nid. The uuid, isbn, oid and
+// ietf namespaces are registered by default.
+func RegisterURNNamespace(nid string, namespace URNNamespace) {
+	urnNamespaces.Lock()
+	defer urnNamespaces.Unlock()
+	urnNamespaces.namespaces[strings.ToLower(nid)] = namespace
+}
+
+func lookupURNNamespace(nid string) (URNNamespace, bool) {
+	urnNamespaces.RLock()
+	defer urnNamespaces.RUnlock()
+	namespace, ok := urnNamespaces.namespaces[strings.ToLower(nid)]
+	return namespace, ok
+}
+
+var nidSyntax = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,30}[A-Za-z0-9]$`)
+
+// ParseURN extracts a URN from an IRI with the "urn" scheme, checking the RFC 8141 syntax and the
+// rules of the namespace when one is registered.
+func ParseURN(iri *IRI) (*URN, error) {
+	if !strings.EqualFold(iri.Scheme, "urn") {
+		return nil, errors.New("not a urn")
+	}
+	if iri.Spans().Host.Present() {
+		return nil, errors.New("a urn cannot have an authority")
+	}
+	nid, nss, ok := strings.Cut(iri.Path, ":")
+	if !ok {
+		return nil, errors.New("urn is missing ':' after the namespace identifier")
+	}
+	if !nidSyntax.MatchString(nid) {
+		return nil, errors.New("invalid urn namespace identifier")
+	}
+	if nss == "" || nss[0] == '/' {
+		return nil, errors.New("invalid urn namespace specific string")
+	}
+	urn := &URN{NID: nid, NSS: nss}
+	if iri.Spans().Query.Present() {
+		if err := urn.parseRQComponents(iri.Query); err != nil {
+			return nil, err
+		}
+	}
+	if iri.Spans().Fragment.Present() {
+		urn.FComponent, urn.HasF = iri.Fragment, true
+	}
+	if namespace, ok := lookupURNNamespace(nid); ok && namespace.Validate != nil {
+		if err := namespace.Validate(nss); err != nil {
+			return nil, err
+		}
+	}
+	return urn, nil
+}
+
+// parseRQComponents splits the iquery of the IRI into [ "+" r-component ] [ "?=" q-component ],
+// the leading '?' having been consumed by the IRI parser.
+func (u *URN) parseRQComponents(query string) error {
+	rest := "?" + query
+	if strings.HasPrefix(rest, "?+") {
+		rest = rest[2:]
+		end := strings.Index(rest, "?=")
+		if end < 0 {
+			end = len(rest)
+		}
+		u.RComponent, u.HasR, rest = rest[:end], true, rest[end:]
+		if !validRQComponent(u.RComponent) {
+			return errors.New("invalid urn r-component")
+		}
+	}
+	if strings.HasPrefix(rest, "?=") {
+		u.QComponent, u.HasQ, rest = rest[2:], true, ""
+		if !validRQComponent(u.QComponent) {
+			return errors.New("invalid urn q-component")
+		}
+	}
+	if rest != "" {
+		return errors.New("urn query must be an r-component or q-component")
+	}
+	return nil
+}
+
+// validRQComponent checks pchar *( pchar / "/" / "?" ), where the IRI parser already checked the
+// characters so only the first one needs to be looked at.
+func validRQComponent(value string) bool {
+	return value != "" && value[0] != '/' && value[0] != '?'
+}
+
+// String returns the URN with all of its components.
+func (u *URN) String() string {
+	b := strings.Builder{}
+	b.WriteString("urn:")
+	b.WriteString(u.NID)
+	b.WriteByte(':')
+	b.WriteString(u.NSS)
+	if u.HasR {
+		b.WriteString("?+")
+		b.WriteString(u.RComponent)
+	}
+	if u.HasQ {
+		b.WriteString("?=")
+		b.WriteString(u.QComponent)
+	}
+	if u.HasF {
+		b.WriteByte('#')
+		b.WriteString(u.FComponent)
+	}
+	return b.String()
+}
+
+// Equivalent reports whether two URNs are lexically equivalent under RFC 8141 section 3.1: the
+// NID is compared case-insensitively, pct-encoding hex digits are compared case-insensitively,
+// the r-, q- and f-components are ignored and any namespace specific normalization is applied.
+func (u *URN) Equivalent(other *URN) bool {
+	return u.equivalenceKey() == other.equivalenceKey()
+}
+
+func (u *URN) equivalenceKey() string {
+	nss := normalizePctEncoding(u.NSS)
+	if namespace, ok := lookupURNNamespace(u.NID); ok && namespace.Normalize != nil {
+		nss = namespace.Normalize(nss)
+	}
+	return "urn:" + strings.ToLower(u.NID) + ":" + nss
+}
+
+var uuidSyntax = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+
+// validateUuidNss checks the RFC 4122 string representation of a UUID.
+func validateUuidNss(nss string) error {
+	if !uuidSyntax.MatchString(nss) {
+		return errors.New("invalid uuid")
+	}
+	return nil
+}
+
+// validateIsbnNss checks an ISBN-10 or ISBN-13 (RFC 8254), hyphens being allowed as separators,
+// including its check digit.
+func validateIsbnNss(nss string) error {
+	digits := normalizeIsbnNss(nss)
+	switch len(digits) {
+	case 10:
+		sum := 0
+		for i, c := range digits {
+			value := int(c - '0')
+			if c == 'X' && i == 9 {
+				value = 10
+			} else if c < '0' || c > '9' {
+				return errors.New("invalid isbn")
+			}
+			sum += (10 - i) * value
+		}
+		if sum%11 != 0 {
+			return errors.New("invalid isbn check digit")
+		}
+	case 13:
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return errors.New("invalid isbn prefix")
+		}
+		sum := 0
+		for i, c := range digits {
+			if c < '0' || c > '9' {
+				return errors.New("invalid isbn")
+			}
+			sum += int(c-'0') * (1 + 2*(i%2))
+		}
+		if sum%10 != 0 {
+			return errors.New("invalid isbn check digit")
+		}
+	default:
+		return errors.New("an isbn has 10 or 13 digits")
+	}
+	return nil
+}
+
+func normalizeIsbnNss(nss string) string {
+	return strings.ToUpper(strings.ReplaceAll(nss, "-", ""))
+}
+
+var oidSyntax = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*))*$`)
+
+// validateOidNss checks the dotted decimal form of an OID (RFC 3061).
+func validateOidNss(nss string) error {
+	if !oidSyntax.MatchString(nss) {
+		return errors.New("invalid oid")
+	}
+	return nil
+}
+
+var ietfNumbered = regexp.MustCompile(`^(?i:rfc|fyi|std|bcp):[1-9][0-9]*$`)
+var ietfNamed = regexp.MustCompile(`^(?i:id|mtg|params):.+$`)
+
+// validateIetfNss checks the document and registry references of RFC 2648.
+func validateIetfNss(nss string) error {
+	if !ietfNumbered.MatchString(nss) && !ietfNamed.MatchString(nss) {
+		return errors.New("invalid ietf urn")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package odin_iri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseURN(t *testing.T) {
+	failSet := []string{
+		"http://example.org/",
+		"urn://authority/x",
+		"urn:isbn",
+		"urn:a:nss",
+		"urn:-ab:nss",
+		"urn:ab-:nss",
+		"urn:abcdefghijklmnopqrstuvwxyz0123456:nss",
+		"urn:ex:/nss",
+		"urn:ex:nss?query",
+		"urn:ex:nss?+",
+		"urn:ex:nss?=",
+		"urn:ex:nss?+/r",
+		"urn:ex:nss?=?q",
+		"urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf",
+		"urn:isbn:0451450524",
+		"urn:isbn:978-0-306-40615-6",
+		"urn:isbn:123",
+		"urn:oid:1.02.3",
+		"urn:oid:1..3",
+		"urn:ietf:rfc:0",
+		"urn:ietf:draft:x",
+	}
+	for _, v := range failSet {
+		iri, err := ParseIri(v)
+		if err != nil {
+			continue
+		}
+		if _, err = ParseURN(iri); err == nil {
+			t.Fatalf("ParseURN should have failed with %s", v)
+		}
+	}
+
+	urn := mustParse(t, "URN:Example:a/b:c?+r=1?x?=q=2/?#frag", ParseURN)
+	if urn.NID != "Example" || urn.NSS != "a/b:c" {
+		t.Fatalf("unexpected nid and nss %+v", urn)
+	}
+	if !urn.HasR || urn.RComponent != "r=1?x" || !urn.HasQ || urn.QComponent != "q=2/?" || !urn.HasF || urn.FComponent != "frag" {
+		t.Fatalf("unexpected r, q and f components %+v", urn)
+	}
+	if urn.String() != "urn:Example:a/b:c?+r=1?x?=q=2/?#frag" {
+		t.Fatalf("unexpected string '%s'", urn.String())
+	}
+
+	urn = mustParse(t, "urn:ex:nss?=only", ParseURN)
+	if urn.HasR || !urn.HasQ || urn.QComponent != "only" || urn.HasF {
+		t.Fatalf("unexpected components %+v", urn)
+	}
+
+	goodSet := []string{
+		"urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+		"urn:isbn:0-451-45052-3",
+		"urn:isbn:080442957X",
+		"urn:isbn:978-0-306-40615-7",
+		"urn:oid:1.3.6.1.4.1",
+		"urn:oid:0",
+		"urn:ietf:rfc:2648",
+		"urn:ietf:params:xml:ns:vcard-4.0",
+		"urn:oasis:names:specification:docbook:dtd:xml:4.1.2",
+		"urn:example:ümlaut",
+	}
+	for _, v := range goodSet {
+		mustParse(t, v, ParseURN)
+	}
+}
+
+func TestURNEquivalence(t *testing.T) {
+	// Pairs taken from the examples of RFC 8141 section 3.2 plus namespace specific rules.
+	equal := [][2]string{
+		{"urn:example:a123,z456", "URN:example:a123,z456"},
+		{"urn:example:a123,z456", "urn:EXAMPLE:a123,z456"},
+		{"urn:example:a123,z456", "urn:example:a123,z456?+abc"},
+		{"urn:example:a123,z456", "urn:example:a123,z456?=xyz"},
+		{"urn:example:a123,z456", "urn:example:a123,z456#789"},
+		{"urn:example:a123%2Cz456", "urn:example:a123%2cz456"},
+		{"urn:uuid:F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6", "urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6"},
+		{"urn:isbn:0-451-45052-3", "urn:ISBN:0451450523"},
+		{"urn:ietf:RFC:2648", "urn:ietf:rfc:2648"},
+	}
+	different := [][2]string{
+		{"urn:example:a123,z456", "urn:example:A123,Z456"},
+		{"urn:example:a123,z456", "urn:example:a123%2Cz456"},
+		{"urn:example:a123,z456", "urn:example:a123,z456/foo"},
+		{"urn:example:a123,z456", "urn:examples:a123,z456"},
+	}
+
+	for _, v := range equal {
+		if !mustParse(t, v[0], ParseURN).Equivalent(mustParse(t, v[1], ParseURN)) {
+			t.Fatalf("'%s' and '%s' should be equivalent", v[0], v[1])
+		}
+	}
+	for _, v := range different {
+		if mustParse(t, v[0], ParseURN).Equivalent(mustParse(t, v[1], ParseURN)) {
+			t.Fatalf("'%s' and '%s' should not be equivalent", v[0], v[1])
+		}
+	}
+}
+
+func TestRegisterURNNamespace(t *testing.T) {
+	RegisterURNNamespace("X-Test", URNNamespace{
+		Validate: func(nss string) error {
+			if nss != "ok" && nss != "OK" {
+				return errors.New("not ok")
+			}
+			return nil
+		},
+		Normalize: func(nss string) string { return "ok" },
+	})
+	iri, _ := ParseIri("urn:x-test:bad")
+	if _, err := ParseURN(iri); err == nil {
+		t.Fatalf("the registered validator should reject the nss")
+	}
+	if !mustParse(t, "urn:x-test:ok", ParseURN).Equivalent(mustParse(t, "urn:X-TEST:OK", ParseURN)) {
+		t.Fatalf("the registered normalizer should be used for equivalence")
+	}
+	if _, err := ParseIri("urn:isbn:0451450524", WithSchemeValidation(nil)); err == nil {
+		t.Fatalf("scheme validation should apply the isbn rules")
+	}
+}
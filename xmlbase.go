@@ -0,0 +1,75 @@
+package odin_iri
+
+import (
+	"errors"
+)
+
+// BaseStack tracks the base IRI of an XML document as its elements are traversed, following XML
+// Base. Each xml:base value pushed is resolved against the base of its parent, and the values of
+// attributes holding references are resolved against the current base.
+type BaseStack struct {
+	bases []*IRI
+}
+
+// NewBaseStack returns a stack whose current base is the base of the document, usually the IRI it
+// was retrieved from. A nil base leaves the document without a base, in which case the first
+// xml:base pushed must be an IRI rather than a relative reference.
+func NewBaseStack(documentBase *IRI) *BaseStack {
+	return &BaseStack{bases: []*IRI{documentBase}}
+}
+
+// Push resolves the LEIRI value of an xml:base attribute against the current base and makes the
+// result the current base, until the matching Pop.
+func (s *BaseStack) Push(xmlBase string) error {
+	base, err := s.Resolve(xmlBase)
+	if err != nil {
+		return err
+	}
+	s.bases = append(s.bases, base)
+	return nil
+}
+
+// Pop restores the base that was current before the last Push, failing when every pushed base has
+// already been popped.
+func (s *BaseStack) Pop() error {
+	if len(s.bases) == 1 {
+		return errors.New("no xml:base left to pop")
+	}
+	s.bases = s.bases[:len(s.bases)-1]
+	return nil
+}
+
+// Current returns the current base, nil when neither the document nor an xml:base provided one.
+func (s *BaseStack) Current() *IRI {
+	return s.bases[len(s.bases)-1]
+}
+
+// Depth returns the number of xml:base values pushed and not yet popped.
+func (s *BaseStack) Depth() int {
+	return len(s.bases) - 1
+}
+
+// Resolve converts the LEIRI reference to an IRI reference and resolves it against the current
+// base. Without a current base the reference must already be an IRI.
+func (s *BaseStack) Resolve(ref string) (*IRI, error) {
+	value := LEIRIToIRI(ref)
+	base := s.Current()
+	if base == nil {
+		return ParseIri(value)
+	}
+	reference, err := ParseIriReference(value)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(reference)
+}
+
+// LEIRIToIRI converts a Legacy Extended IRI, the form of the xml:base attribute and of XML system
+// identifiers, to an IRI reference. The characters a LEIRI allows beyond an IRI, such as controls,
+// space, <>"{}|\^` and private use characters, are percent-encoded as UTF-8, as are invalid UTF-8
+// bytes. Existing percent-encodings are left as they are.
+func LEIRIToIRI(value string) string {
+	return pctEncode(value, func(r rune) bool {
+		return isUnreserved(r) || isReserved(r) || isUcsChar(r) || r == '%'
+	})
+}
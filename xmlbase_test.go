@@ -0,0 +1,135 @@
+package odin_iri
+
+import (
+	"testing"
+)
+
+func TestLEIRIToIRI(t *testing.T) {
+	set := map[string]string{
+		"http://example.org/a b":      "http://example.org/a%20b",
+		"http://example.org/<x>":      "http://example.org/%3Cx%3E",
+		"http://example.org/{a|b}":    "http://example.org/%7Ba%7Cb%7D",
+		"http://example.org/\"^`\\":   "http://example.org/%22%5E%60%5C",
+		"http://example.org/\t\n":     "http://example.org/%09%0A",
+		"http://example.org/\u0085":   "http://example.org/%C2%85",
+		"http://example.org/\ue000":   "http://example.org/%EE%80%80",
+		"http://example.org/\ufff0":   "http://example.org/%EF%BF%B0",
+		"http://example.org/\xff":     "http://example.org/%FF",
+		"http://example.org/été?q#f":  "http://example.org/été?q#f",
+		"http://example.org/a%20b":    "http://example.org/a%20b",
+		"http://[::1]:80/;a=b&c$!*'(": "http://[::1]:80/;a=b&c$!*'(",
+		"":                            "",
+	}
+
+	for v, e := range set {
+		if converted := LEIRIToIRI(v); converted != e {
+			t.Fatalf("'%s' should convert to '%s', got '%s'", v, e, converted)
+		}
+	}
+}
+
+func TestBaseStack(t *testing.T) {
+	document, err := ParseIri("http://example.org/today/index.xml")
+	if err != nil {
+		t.Fatalf("ParseIri should succeed: %s", err.Error())
+	}
+	s := NewBaseStack(document)
+
+	steps := []struct {
+		push, base string
+	}{
+		{"http://example.org/hotpicks/", "http://example.org/hotpicks/"},
+		{"pick1/", "http://example.org/hotpicks/pick1/"},
+		{"../new picks/", "http://example.org/hotpicks/new%20picks/"},
+		{"", "http://example.org/hotpicks/new%20picks/"},
+		{"#frag", "http://example.org/hotpicks/new%20picks/#frag"},
+		{"//other.org/<x>", "http://other.org/%3Cx%3E"},
+	}
+
+	for d, step := range steps {
+		if err := s.Push(step.push); err != nil {
+			t.Fatalf("Push should succeed with '%s': %s", step.push, err.Error())
+		}
+		if s.Current().Value != step.base {
+			t.Fatalf("'%s' should give the base '%s', got '%s'", step.push, step.base, s.Current().Value)
+		}
+		if s.Depth() != d+1 {
+			t.Fatalf("depth should be %d, got %d", d+1, s.Depth())
+		}
+	}
+
+	for d := len(steps) - 1; d > 0; d-- {
+		if err := s.Pop(); err != nil {
+			t.Fatalf("Pop should succeed: %s", err.Error())
+		}
+		if s.Current().Value != steps[d-1].base {
+			t.Fatalf("Pop should restore '%s', got '%s'", steps[d-1].base, s.Current().Value)
+		}
+	}
+
+	refs := map[string]string{
+		"picture.jpg": "http://example.org/hotpicks/picture.jpg",
+		"a b.jpg":     "http://example.org/hotpicks/a%20b.jpg",
+		"/top":        "http://example.org/top",
+		"?q={x}":      "http://example.org/hotpicks/?q=%7Bx%7D",
+		"mailto:x@y":  "mailto:x@y",
+	}
+
+	for v, e := range refs {
+		iri, err := s.Resolve(v)
+		if err != nil {
+			t.Fatalf("Resolve should succeed with '%s': %s", v, err.Error())
+		}
+		if iri.Value != e {
+			t.Fatalf("'%s' should resolve to '%s', got '%s'", v, e, iri.Value)
+		}
+	}
+
+	if err := s.Pop(); err != nil {
+		t.Fatalf("Pop should succeed: %s", err.Error())
+	}
+	if s.Current() != document || s.Depth() != 0 {
+		t.Fatalf("Pop should restore the document base, got '%s'", s.Current().Value)
+	}
+	if err := s.Pop(); err == nil {
+		t.Fatalf("Pop should fail without a pushed base")
+	}
+
+	failSet := []string{
+		"http://example.org/%zz",
+		"http://[example.org/",
+		"a:b:c[",
+	}
+
+	for _, v := range failSet {
+		if err := s.Push(v); err == nil {
+			t.Fatalf("Push should fail with '%s', got '%s'", v, s.Current().Value)
+		}
+	}
+	if s.Depth() != 0 {
+		t.Fatalf("a failed Push should leave the stack as it was")
+	}
+}
+
+func TestBaseStackWithoutDocumentBase(t *testing.T) {
+	s := NewBaseStack(nil)
+	if s.Current() != nil {
+		t.Fatalf("the stack should start without a base")
+	}
+	if err := s.Push("relative/"); err == nil {
+		t.Fatalf("Push should fail with a relative reference and no base")
+	}
+	if _, err := s.Resolve("#x"); err == nil {
+		t.Fatalf("Resolve should fail with a relative reference and no base")
+	}
+	if err := s.Push("http://example.org/a b/"); err != nil {
+		t.Fatalf("Push should succeed with an IRI: %s", err.Error())
+	}
+	iri, err := s.Resolve("c")
+	if err != nil {
+		t.Fatalf("Resolve should succeed: %s", err.Error())
+	}
+	if iri.Value != "http://example.org/a%20b/c" {
+		t.Fatalf("'c' should resolve to 'http://example.org/a%%20b/c', got '%s'", iri.Value)
+	}
+}